@@ -0,0 +1,27 @@
+package platform
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	platformInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "operatorutils_platform_info",
+			Help: "Platform detected by the last platform detection, with a constant value of 1",
+		},
+		[]string{"platform", "kubernetes_version", "os"},
+	)
+	openShiftVersionInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "operatorutils_openshift_version_info",
+			Help: "OpenShift version found by the last version lookup, with a constant value of 1",
+		},
+		[]string{"version"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(platformInfo, openShiftVersionInfo)
+}
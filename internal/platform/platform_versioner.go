@@ -126,6 +126,8 @@ func (pv K8SBasedPlatformVersioner) GetPlatformInfo(client Discoverer, cfg *rest
 		}
 	}
 	log.Info(info.String())
+	platformInfo.Reset()
+	platformInfo.WithLabelValues(string(info.Name), info.K8SVersion, info.OS).Set(1)
 	return info, nil
 }
 
@@ -176,6 +178,8 @@ func (pv K8SBasedPlatformVersioner) LookupOpenShiftVersion(client Discoverer, cf
 		}
 		osv.Version = cvi.Status.Desired.Version
 	}
+	openShiftVersionInfo.Reset()
+	openShiftVersionInfo.WithLabelValues(osv.Version).Set(1)
 	return osv, nil
 }
 
@@ -5,6 +5,7 @@ import (
 
 	openapi_v2 "github.com/google/gnostic/openapiv2"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/version"
@@ -132,6 +133,9 @@ func TestK8SBasedPlatformVersioner_GetPlatformInfo(t *testing.T) {
 		assert.Equal(t, c.expectedInfo, info, c.label+": mismatch in returned PlatformInfo")
 		if c.expectedErr {
 			assert.Error(t, err, c.label+": expected error, but none occurred")
+		} else {
+			assert.Equal(t, float64(1), testutil.ToFloat64(platformInfo.WithLabelValues(string(info.Name), info.K8SVersion, info.OS)), c.label+": expected platform info metric to be set")
+			assert.Equal(t, 1, testutil.CollectAndCount(platformInfo), c.label+": expected a single platform info metric")
 		}
 	}
 }
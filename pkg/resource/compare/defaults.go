@@ -1,32 +1,50 @@
 package compare
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"sort"
 	"strings"
+	"sync"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/go-test/deep"
 	oappsv1 "github.com/openshift/api/apps/v1"
 	buildv1 "github.com/openshift/api/build/v1"
+	consolev1 "github.com/openshift/api/console/v1"
+	imagev1 "github.com/openshift/api/image/v1"
 	routev1 "github.com/openshift/api/route/v1"
+	templatev1 "github.com/openshift/api/template/v1"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 const (
 	imageTriggersAnnotation           = "image.openshift.io/triggers"
 	deploymentRevisionAnnotation      = "deployment.kubernetes.io/revision"
 	imageTriggerContainerNameValueFmt = "spec.template.spec.containers[?(@.name==\\\"%s\\\")].image"
+	dockerRepositoryCheckAnnotation   = "openshift.io/image.dockerRepositoryCheck"
+)
+
+var (
+	semantic      = equality.Semantic
+	semanticTypes sync.Map
 )
 
 type resourceComparator struct {
 	defaultCompareFunc func(deployed client.Object, requested client.Object) bool
 	compareFuncMap     map[reflect.Type]func(deployed client.Object, requested client.Object) bool
+	fieldManager       string
 }
 
 func (this *resourceComparator) SetDefaultComparator(compFunc func(deployed client.Object, requested client.Object) bool) {
@@ -53,8 +71,15 @@ func (this *resourceComparator) Compare(deployed client.Object, requested client
 		if comparator, exists := this.compareFuncMap[type1]; exists {
 			compareFunc = comparator
 		}
+		if this.fieldManager != "" {
+			deployed = ignoreForeignFields(deployed, requested, this.fieldManager)
+		}
+	}
+	equal := compareFunc(deployed, requested)
+	if !equal {
+		mismatches.WithLabelValues(type2.Name()).Inc()
 	}
-	return compareFunc(deployed, requested)
+	return equal
 }
 
 func (this *resourceComparator) CompareArrays(deployed []client.Object, requested []client.Object) ResourceDelta {
@@ -102,6 +127,17 @@ func defaultMap() map[reflect.Type]func(deployed client.Object, requested client
 	equalsMap[reflect.TypeOf(corev1.ServiceAccount{})] = equalServiceAccounts
 	equalsMap[reflect.TypeOf(corev1.Secret{})] = equalSecrets
 	equalsMap[reflect.TypeOf(buildv1.BuildConfig{})] = equalBuildConfigs
+	equalsMap[reflect.TypeOf(rbacv1.ClusterRole{})] = equalClusterRoles
+	equalsMap[reflect.TypeOf(rbacv1.ClusterRoleBinding{})] = equalClusterRoleBindings
+	equalsMap[reflect.TypeOf(networkingv1.NetworkPolicy{})] = equalNetworkPolicies
+	equalsMap[reflect.TypeOf(policyv1.PodDisruptionBudget{})] = equalPodDisruptionBudgets
+	equalsMap[reflect.TypeOf(autoscalingv2.HorizontalPodAutoscaler{})] = equalHorizontalPodAutoscalers
+	equalsMap[reflect.TypeOf(corev1.ResourceQuota{})] = equalResourceQuotas
+	equalsMap[reflect.TypeOf(imagev1.ImageStream{})] = equalImageStreams
+	equalsMap[reflect.TypeOf(templatev1.Template{})] = equalTemplates
+	equalsMap[reflect.TypeOf(consolev1.ConsoleYAMLSample{})] = equalConsoleYAMLSamples
+	equalsMap[reflect.TypeOf(consolev1.ConsoleLink{})] = equalConsoleLinks
+	equalsMap[reflect.TypeOf(consolev1.ConsoleQuickStart{})] = equalConsoleQuickStarts
 	return equalsMap
 }
 
@@ -111,6 +147,7 @@ func equalDeploymentConfigs(deployed client.Object, requested client.Object) boo
 
 	//Removed generated fields from deployed version, when not specified in requested item
 	dc1 = dc1.DeepCopy()
+	dc2 = dc2.DeepCopy()
 	triggerBasedImage := make(map[string]bool)
 	if dc2.Spec.Strategy.RecreateParams == nil {
 		dc1.Spec.Strategy.RecreateParams = nil
@@ -192,6 +229,7 @@ func equalDeployment(deployed client.Object, requested client.Object) bool {
 	d2 := requested.(*appsv1.Deployment)
 
 	d1 = d1.DeepCopy()
+	d2 = d2.DeepCopy()
 	triggerBasedImage := make(map[string]bool)
 
 	if d2.Spec.Strategy.RollingUpdate == nil && d1.Spec.Strategy.RollingUpdate != nil {
@@ -267,6 +305,9 @@ func sortBuildConfigVars(bc1 *buildv1.BuildConfig, bc2 *buildv1.BuildConfig) {
 }
 
 func sortDeploymentVars(pod1 *corev1.PodTemplateSpec, pod2 *corev1.PodTemplateSpec) {
+	if pod1 == nil || pod2 == nil {
+		return
+	}
 	for index := range pod1.Spec.Containers {
 		if len(pod2.Spec.Containers) <= index {
 			logger.Info("No matching container found in requested resource", "deployed container", pod1.Spec.Containers[index])
@@ -284,7 +325,13 @@ func sortDeploymentVars(pod1 *corev1.PodTemplateSpec, pod2 *corev1.PodTemplateSp
 	}
 }
 
+// sortEnvVars sorts both lists of environment variables by name, so that a different ordering is not reported as a change.
+// Kubernetes expands $(VAR) references using variables declared earlier in the same list, so when any entry of either
+// list references another one, ordering is meaningful and the lists are left untouched, causing a reorder to be an update
 func sortEnvVars(envs1 []corev1.EnvVar, envs2 []corev1.EnvVar) {
+	if hasEnvVarReferences(envs1) || hasEnvVarReferences(envs2) {
+		return
+	}
 	sort.Slice(envs1, func(i, j int) bool {
 		return envs1[i].Name < envs1[j].Name
 	})
@@ -293,6 +340,47 @@ func sortEnvVars(envs1 []corev1.EnvVar, envs2 []corev1.EnvVar) {
 	})
 }
 
+// hasEnvVarReferences returns true if the value of any of the environment variables references another one from the same list
+func hasEnvVarReferences(envs []corev1.EnvVar) bool {
+	names := make(map[string]bool, len(envs))
+	for _, env := range envs {
+		names[env.Name] = true
+	}
+	for _, env := range envs {
+		for _, reference := range getEnvVarReferences(env.Value) {
+			if reference != env.Name && names[reference] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getEnvVarReferences returns the variable names referenced through the $(VAR) syntax, skipping escaped $$(VAR) sequences
+func getEnvVarReferences(value string) []string {
+	var references []string
+	for i := 0; i < len(value)-1; i++ {
+		if value[i] != '$' {
+			continue
+		}
+		if value[i+1] == '$' {
+			//Escaped reference, not expanded
+			i++
+			continue
+		}
+		if value[i+1] != '(' {
+			continue
+		}
+		end := strings.IndexByte(value[i+2:], ')')
+		if end < 0 {
+			break
+		}
+		references = append(references, value[i+2:i+2+end])
+		i += end + 2
+	}
+	return references
+}
+
 func checkGeneratePodValues(pod1 *corev1.PodTemplateSpec, pod2 *corev1.PodTemplateSpec, triggerBasedImage map[string]bool) bool {
 	if pod1 != nil && pod2 != nil {
 		for i := range pod1.Spec.Volumes {
@@ -360,6 +448,13 @@ func ignoreGenerateContainerValues(containers1 []corev1.Container, containers2 [
 				}
 			}
 		}
+		ignoreNumericProbePorts(containers1[i].LivenessProbe, containers2[i].LivenessProbe)
+		ignoreNumericProbePorts(containers1[i].ReadinessProbe, containers2[i].ReadinessProbe)
+		ignoreNumericProbePorts(containers1[i].StartupProbe, containers2[i].StartupProbe)
+		if containers1[i].Lifecycle != nil && containers2[i].Lifecycle != nil {
+			ignoreNumericLifecyclePorts(containers1[i].Lifecycle.PostStart, containers2[i].Lifecycle.PostStart)
+			ignoreNumericLifecyclePorts(containers1[i].Lifecycle.PreStop, containers2[i].Lifecycle.PreStop)
+		}
 		if containers2[i].TerminationMessagePath == "" {
 			containers1[i].TerminationMessagePath = ""
 		}
@@ -368,7 +463,7 @@ func ignoreGenerateContainerValues(containers1 []corev1.Container, containers2 [
 		}
 		for j := range containers1[i].Env {
 			if len(containers2[i].Env) <= j {
-				return
+				break
 			}
 			valueFrom := containers2[i].Env[j].ValueFrom
 			if valueFrom != nil && valueFrom.FieldRef != nil && valueFrom.FieldRef.APIVersion == "" {
@@ -424,6 +519,11 @@ func equalServices(deployed client.Object, requested client.Object) bool {
 			if port2.Protocol == "" {
 				port1.Protocol = ""
 			}
+			if port2.TargetPort == (intstr.IntOrString{}) {
+				//Target port is defaulted to the port number
+				port1.TargetPort = port2.TargetPort
+			}
+			ignoreNumericPortString(&port1.TargetPort, &port2.TargetPort)
 		}
 	}
 	ignoreEmptyMaps(service1, service2)
@@ -476,6 +576,9 @@ func equalRoutes(deployed client.Object, requested client.Object) bool {
 	if route2.Spec.WildcardPolicy == "" {
 		route1.Spec.WildcardPolicy = ""
 	}
+	if route1.Spec.Port != nil && route2.Spec.Port != nil {
+		ignoreNumericPortString(&route1.Spec.Port.TargetPort, &route2.Spec.Port.TargetPort)
+	}
 	ignoreEmptyMaps(route1, route2)
 
 	var pairs [][2]interface{}
@@ -515,6 +618,209 @@ func equalRoles(deployed client.Object, requested client.Object) bool {
 	return equal
 }
 
+func equalClusterRoles(deployed client.Object, requested client.Object) bool {
+	role1 := deployed.(*rbacv1.ClusterRole)
+	role2 := requested.(*rbacv1.ClusterRole)
+	var pairs [][2]interface{}
+	pairs = append(pairs, [2]interface{}{role1.Name, role2.Name})
+	pairs = append(pairs, [2]interface{}{role1.Labels, role2.Labels})
+	pairs = append(pairs, [2]interface{}{role1.Annotations, role2.Annotations})
+	pairs = append(pairs, [2]interface{}{role1.AggregationRule, role2.AggregationRule})
+	if role2.AggregationRule == nil {
+		//Rules of an aggregated cluster role are populated by the aggregation controller
+		pairs = append(pairs, [2]interface{}{role1.Rules, role2.Rules})
+	}
+	equal := EqualPairs(pairs)
+	if !equal {
+		if logger.GetSink().Enabled(1) {
+			logger.V(1).Info("Resources are not equal", "deployed", deployed, "requested", requested)
+		} else {
+			logger.Info("Resources are not equal. For more details set the Operator log level to DEBUG.")
+		}
+	}
+	return equal
+}
+
+func equalClusterRoleBindings(deployed client.Object, requested client.Object) bool {
+	binding1 := deployed.(*rbacv1.ClusterRoleBinding)
+	binding2 := requested.(*rbacv1.ClusterRoleBinding)
+	var pairs [][2]interface{}
+	pairs = append(pairs, [2]interface{}{binding1.Name, binding2.Name})
+	pairs = append(pairs, [2]interface{}{binding1.Labels, binding2.Labels})
+	pairs = append(pairs, [2]interface{}{binding1.Annotations, binding2.Annotations})
+	pairs = append(pairs, [2]interface{}{binding1.Subjects, binding2.Subjects})
+	pairs = append(pairs, [2]interface{}{binding1.RoleRef, binding2.RoleRef})
+	equal := EqualPairs(pairs)
+	if !equal {
+		if logger.GetSink().Enabled(1) {
+			logger.V(1).Info("Resources are not equal", "deployed", deployed, "requested", requested)
+		} else {
+			logger.Info("Resources are not equal. For more details set the Operator log level to DEBUG.")
+		}
+	}
+	return equal
+}
+
+func equalNetworkPolicies(deployed client.Object, requested client.Object) bool {
+	policy1 := deployed.(*networkingv1.NetworkPolicy)
+	policy2 := requested.(*networkingv1.NetworkPolicy)
+
+	//Removed generated fields from deployed version, when not specified in requested item
+	policy1 = policy1.DeepCopy()
+	if len(policy2.Spec.PolicyTypes) == 0 {
+		policy1.Spec.PolicyTypes = nil
+	}
+	for i := range policy1.Spec.Ingress {
+		if len(policy2.Spec.Ingress) <= i {
+			break
+		}
+		ignoreGeneratedNetworkPolicyPortValues(policy1.Spec.Ingress[i].Ports, policy2.Spec.Ingress[i].Ports)
+	}
+	for i := range policy1.Spec.Egress {
+		if len(policy2.Spec.Egress) <= i {
+			break
+		}
+		ignoreGeneratedNetworkPolicyPortValues(policy1.Spec.Egress[i].Ports, policy2.Spec.Egress[i].Ports)
+	}
+	ignoreEmptyMaps(policy1, policy2)
+
+	var pairs [][2]interface{}
+	pairs = append(pairs, [2]interface{}{policy1.Name, policy2.Name})
+	pairs = append(pairs, [2]interface{}{policy1.Namespace, policy2.Namespace})
+	pairs = append(pairs, [2]interface{}{policy1.Labels, policy2.Labels})
+	pairs = append(pairs, [2]interface{}{policy1.Annotations, policy2.Annotations})
+	pairs = append(pairs, [2]interface{}{policy1.Spec, policy2.Spec})
+	equal := EqualPairs(pairs)
+	if !equal {
+		if logger.GetSink().Enabled(1) {
+			logger.V(1).Info("Resources are not equal", "deployed", deployed, "requested", requested)
+		} else {
+			logger.Info("Resources are not equal. For more details set the Operator log level to DEBUG.")
+		}
+	}
+	return equal
+}
+
+func ignoreGeneratedNetworkPolicyPortValues(ports1 []networkingv1.NetworkPolicyPort, ports2 []networkingv1.NetworkPolicyPort) {
+	for i := range ports1 {
+		if len(ports2) <= i {
+			return
+		}
+		if ports2[i].Protocol == nil {
+			ports1[i].Protocol = nil
+		}
+		ignoreNumericPortString(ports1[i].Port, ports2[i].Port)
+	}
+}
+
+func equalPodDisruptionBudgets(deployed client.Object, requested client.Object) bool {
+	pdb1 := deployed.(*policyv1.PodDisruptionBudget)
+	pdb2 := requested.(*policyv1.PodDisruptionBudget)
+
+	//Removed generated fields from deployed version, when not specified in requested item
+	pdb1 = pdb1.DeepCopy()
+	if pdb2.Spec.UnhealthyPodEvictionPolicy == nil {
+		pdb1.Spec.UnhealthyPodEvictionPolicy = nil
+	}
+	ignoreEmptyMaps(pdb1, pdb2)
+
+	var pairs [][2]interface{}
+	pairs = append(pairs, [2]interface{}{pdb1.Name, pdb2.Name})
+	pairs = append(pairs, [2]interface{}{pdb1.Namespace, pdb2.Namespace})
+	pairs = append(pairs, [2]interface{}{pdb1.Labels, pdb2.Labels})
+	pairs = append(pairs, [2]interface{}{pdb1.Annotations, pdb2.Annotations})
+	pairs = append(pairs, [2]interface{}{pdb1.Spec, pdb2.Spec})
+	equal := EqualPairs(pairs)
+	if !equal {
+		if logger.GetSink().Enabled(1) {
+			logger.V(1).Info("Resources are not equal", "deployed", deployed, "requested", requested)
+		} else {
+			logger.Info("Resources are not equal. For more details set the Operator log level to DEBUG.")
+		}
+	}
+	return equal
+}
+
+func equalHorizontalPodAutoscalers(deployed client.Object, requested client.Object) bool {
+	hpa1 := deployed.(*autoscalingv2.HorizontalPodAutoscaler)
+	hpa2 := requested.(*autoscalingv2.HorizontalPodAutoscaler)
+
+	//Removed generated fields from deployed version, when not specified in requested item
+	hpa1 = hpa1.DeepCopy()
+	if hpa2.Spec.MinReplicas == nil {
+		hpa1.Spec.MinReplicas = nil
+	}
+	if len(hpa2.Spec.Metrics) == 0 {
+		//Defaults to a CPU utilization metric
+		hpa1.Spec.Metrics = nil
+	}
+	if hpa2.Spec.Behavior == nil {
+		hpa1.Spec.Behavior = nil
+	}
+	if hpa1.Spec.Behavior != nil && hpa2.Spec.Behavior != nil {
+		if hpa2.Spec.Behavior.ScaleUp == nil {
+			hpa1.Spec.Behavior.ScaleUp = nil
+		}
+		if hpa2.Spec.Behavior.ScaleDown == nil {
+			hpa1.Spec.Behavior.ScaleDown = nil
+		}
+		ignoreGeneratedScalingRulesValues(hpa1.Spec.Behavior.ScaleUp, hpa2.Spec.Behavior.ScaleUp)
+		ignoreGeneratedScalingRulesValues(hpa1.Spec.Behavior.ScaleDown, hpa2.Spec.Behavior.ScaleDown)
+	}
+	ignoreEmptyMaps(hpa1, hpa2)
+
+	var pairs [][2]interface{}
+	pairs = append(pairs, [2]interface{}{hpa1.Name, hpa2.Name})
+	pairs = append(pairs, [2]interface{}{hpa1.Namespace, hpa2.Namespace})
+	pairs = append(pairs, [2]interface{}{hpa1.Labels, hpa2.Labels})
+	pairs = append(pairs, [2]interface{}{hpa1.Annotations, hpa2.Annotations})
+	pairs = append(pairs, [2]interface{}{hpa1.Spec, hpa2.Spec})
+	equal := EqualPairs(pairs)
+	if !equal {
+		if logger.GetSink().Enabled(1) {
+			logger.V(1).Info("Resources are not equal", "deployed", deployed, "requested", requested)
+		} else {
+			logger.Info("Resources are not equal. For more details set the Operator log level to DEBUG.")
+		}
+	}
+	return equal
+}
+
+func ignoreGeneratedScalingRulesValues(rules1 *autoscalingv2.HPAScalingRules, rules2 *autoscalingv2.HPAScalingRules) {
+	if rules1 == nil || rules2 == nil {
+		return
+	}
+	if rules2.StabilizationWindowSeconds == nil {
+		rules1.StabilizationWindowSeconds = nil
+	}
+	if rules2.SelectPolicy == nil {
+		rules1.SelectPolicy = nil
+	}
+	if len(rules2.Policies) == 0 {
+		rules1.Policies = nil
+	}
+}
+
+func equalResourceQuotas(deployed client.Object, requested client.Object) bool {
+	quota1 := deployed.(*corev1.ResourceQuota)
+	quota2 := requested.(*corev1.ResourceQuota)
+	var pairs [][2]interface{}
+	pairs = append(pairs, [2]interface{}{quota1.Name, quota2.Name})
+	pairs = append(pairs, [2]interface{}{quota1.Namespace, quota2.Namespace})
+	pairs = append(pairs, [2]interface{}{quota1.Labels, quota2.Labels})
+	pairs = append(pairs, [2]interface{}{quota1.Annotations, quota2.Annotations})
+	pairs = append(pairs, [2]interface{}{quota1.Spec, quota2.Spec})
+	equal := EqualPairs(pairs)
+	if !equal {
+		if logger.GetSink().Enabled(1) {
+			logger.V(1).Info("Resources are not equal", "deployed", deployed, "requested", requested)
+		} else {
+			logger.Info("Resources are not equal. For more details set the Operator log level to DEBUG.")
+		}
+	}
+	return equal
+}
+
 func equalServiceAccounts(deployed client.Object, requested client.Object) bool {
 	sa1 := deployed.(*corev1.ServiceAccount)
 	sa2 := requested.(*corev1.ServiceAccount)
@@ -602,8 +908,8 @@ func equalBuildConfigs(deployed client.Object, requested client.Object) bool {
 		bc1.Spec.RunPolicy = ""
 	}
 	for i := range bc1.Spec.Triggers {
-		if len(bc1.Spec.Triggers) <= i {
-			return false
+		if len(bc2.Spec.Triggers) <= i {
+			break
 		}
 		trigger1 := bc1.Spec.Triggers[i]
 		trigger2 := bc2.Spec.Triggers[i]
@@ -622,12 +928,24 @@ func equalBuildConfigs(deployed client.Object, requested client.Object) bool {
 				}
 			}
 		}
+		if trigger2.ImageChange == nil && trigger1.ImageChange != nil && trigger2.Type == buildv1.ImageChangeBuildTriggerType {
+			//The server defaults an empty image change for an ImageChange trigger, which is then populated with the triggered image
+			imageChange := *trigger1.ImageChange
+			imageChange.LastTriggeredImageID = ""
+			if reflect.DeepEqual(imageChange, buildv1.ImageChangeTrigger{}) {
+				bc1.Spec.Triggers[i].ImageChange = nil
+			}
+		}
 		if trigger2.ImageChange != nil && trigger1.ImageChange != nil {
 			if trigger2.ImageChange.LastTriggeredImageID == "" {
 				trigger1.ImageChange.LastTriggeredImageID = ""
 			}
 		}
 	}
+	if len(bc1.Spec.Triggers) != len(bc2.Spec.Triggers) {
+		logger.Info("Triggers of the deployed and requested BuildConfigs do not match", "deployed", len(bc1.Spec.Triggers), "requested", len(bc2.Spec.Triggers))
+		return false
+	}
 	if bc2.Spec.SuccessfulBuildsHistoryLimit == nil {
 		bc1.Spec.SuccessfulBuildsHistoryLimit = nil
 	}
@@ -654,14 +972,224 @@ func equalBuildConfigs(deployed client.Object, requested client.Object) bool {
 	return equal
 }
 
-func deepEquals(deployed client.Object, requested client.Object) bool {
-	struct1 := reflect.ValueOf(deployed).Elem().Type()
-	if field1, found1 := struct1.FieldByName("Spec"); found1 {
-		struct2 := reflect.ValueOf(requested).Elem().Type()
-		if field2, found2 := struct2.FieldByName("Spec"); found2 {
-			return Equals(field1, field2)
+func equalImageStreams(deployed client.Object, requested client.Object) bool {
+	is1 := deployed.(*imagev1.ImageStream)
+	is2 := requested.(*imagev1.ImageStream)
+
+	//Removed generated fields from deployed version, when not specified in requested item
+	is1 = is1.DeepCopy()
+	is2 = is2.DeepCopy()
+	delete(is1.Annotations, dockerRepositoryCheckAnnotation)
+	if is2.Spec.DockerImageRepository == "" {
+		is1.Spec.DockerImageRepository = ""
+	}
+	matched := make(map[int]bool, len(is1.Spec.Tags))
+	for _, tag2 := range is2.Spec.Tags {
+		if found, tag1 := findTagReference(tag2.Name, is1.Spec.Tags, matched); found {
+			if tag2.Generation == nil {
+				tag1.Generation = nil
+			}
+			if tag2.ReferencePolicy.Type == "" {
+				tag1.ReferencePolicy.Type = ""
+			}
+			if tag2.ImportPolicy.ImportMode == "" {
+				tag1.ImportPolicy.ImportMode = ""
+			}
+		}
+	}
+	//Tags are keyed by name, so their order is not significant
+	sortTagReferences(is1.Spec.Tags)
+	sortTagReferences(is2.Spec.Tags)
+	ignoreEmptyMaps(is1, is2)
+
+	var pairs [][2]interface{}
+	pairs = append(pairs, [2]interface{}{is1.Name, is2.Name})
+	pairs = append(pairs, [2]interface{}{is1.Namespace, is2.Namespace})
+	pairs = append(pairs, [2]interface{}{is1.Labels, is2.Labels})
+	pairs = append(pairs, [2]interface{}{is1.Annotations, is2.Annotations})
+	pairs = append(pairs, [2]interface{}{is1.Spec, is2.Spec})
+	equal := EqualPairs(pairs)
+	if !equal {
+		if logger.GetSink().Enabled(1) {
+			logger.V(1).Info("Resources are not equal", "deployed", deployed, "requested", requested)
+		} else {
+			logger.Info("Resources are not equal. For more details set the Operator log level to DEBUG.")
+		}
+	}
+	return equal
+}
+
+// findTagReference returns the first tag of the given name that is not matched yet, and marks it as matched,
+// so that tags of a duplicate name are paired in order rather than all matching the first one
+func findTagReference(name string, tags []imagev1.TagReference, matched map[int]bool) (bool, *imagev1.TagReference) {
+	for index := range tags {
+		if tags[index].Name == name && !matched[index] {
+			matched[index] = true
+			return true, &tags[index]
+		}
+	}
+	return false, &imagev1.TagReference{}
+}
+
+func sortTagReferences(tags []imagev1.TagReference) {
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].Name < tags[j].Name
+	})
+}
+
+func equalTemplates(deployed client.Object, requested client.Object) bool {
+	template1 := deployed.(*templatev1.Template)
+	template2 := requested.(*templatev1.Template)
+	//Deployed objects are returned as raw JSON, while requested ones may be typed
+	var objects1, objects2 interface{}
+	contents1, err1 := getRawExtensionContents(template1.Objects)
+	contents2, err2 := getRawExtensionContents(template2.Objects)
+	if err1 != nil || err2 != nil {
+		//Objects that cannot be decoded are compared as they are
+		logger.V(1).Info("Failed to read template objects", "deployed.error", err1, "requested.error", err2)
+		objects1, objects2 = template1.Objects, template2.Objects
+	} else {
+		objects1, objects2 = contents1, contents2
+	}
+	var pairs [][2]interface{}
+	pairs = append(pairs, [2]interface{}{template1.Name, template2.Name})
+	pairs = append(pairs, [2]interface{}{template1.Namespace, template2.Namespace})
+	pairs = append(pairs, [2]interface{}{template1.Labels, template2.Labels})
+	pairs = append(pairs, [2]interface{}{template1.Annotations, template2.Annotations})
+	pairs = append(pairs, [2]interface{}{template1.Message, template2.Message})
+	pairs = append(pairs, [2]interface{}{objects1, objects2})
+	pairs = append(pairs, [2]interface{}{template1.Parameters, template2.Parameters})
+	pairs = append(pairs, [2]interface{}{template1.ObjectLabels, template2.ObjectLabels})
+	equal := EqualPairs(pairs)
+	if !equal {
+		if logger.GetSink().Enabled(1) {
+			logger.V(1).Info("Resources are not equal", "deployed", deployed, "requested", requested)
+		} else {
+			logger.Info("Resources are not equal. For more details set the Operator log level to DEBUG.")
+		}
+	}
+	return equal
+}
+
+func getRawExtensionContents(extensions []runtime.RawExtension) ([]interface{}, error) {
+	var contents []interface{}
+	for _, extension := range extensions {
+		raw := extension.Raw
+		if raw == nil && extension.Object != nil {
+			var err error
+			raw, err = json.Marshal(extension.Object)
+			if err != nil {
+				return nil, err
+			}
+		}
+		var content interface{}
+		if raw != nil {
+			err := json.Unmarshal(raw, &content)
+			if err != nil {
+				return nil, err
+			}
+		}
+		contents = append(contents, removeEmptyValues(content))
+	}
+	return contents, nil
+}
+
+// removeEmptyValues removes null values, as well as empty maps and arrays, which appear when marshalling typed objects
+func removeEmptyValues(content interface{}) interface{} {
+	switch typed := content.(type) {
+	case map[string]interface{}:
+		for key, value := range typed {
+			value = removeEmptyValues(value)
+			if isEmptyValue(value) {
+				delete(typed, key)
+			} else {
+				typed[key] = value
+			}
+		}
+	case []interface{}:
+		for index := range typed {
+			typed[index] = removeEmptyValues(typed[index])
+		}
+	}
+	return content
+}
+
+func isEmptyValue(value interface{}) bool {
+	switch typed := value.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		return len(typed) == 0
+	case []interface{}:
+		return len(typed) == 0
+	}
+	return false
+}
+
+func equalConsoleYAMLSamples(deployed client.Object, requested client.Object) bool {
+	sample1 := deployed.(*consolev1.ConsoleYAMLSample)
+	sample2 := requested.(*consolev1.ConsoleYAMLSample)
+	var pairs [][2]interface{}
+	pairs = append(pairs, [2]interface{}{sample1.Name, sample2.Name})
+	pairs = append(pairs, [2]interface{}{sample1.Labels, sample2.Labels})
+	pairs = append(pairs, [2]interface{}{sample1.Annotations, sample2.Annotations})
+	pairs = append(pairs, [2]interface{}{sample1.Spec, sample2.Spec})
+	equal := EqualPairs(pairs)
+	if !equal {
+		if logger.GetSink().Enabled(1) {
+			logger.V(1).Info("Resources are not equal", "deployed", deployed, "requested", requested)
+		} else {
+			logger.Info("Resources are not equal. For more details set the Operator log level to DEBUG.")
+		}
+	}
+	return equal
+}
+
+func equalConsoleLinks(deployed client.Object, requested client.Object) bool {
+	link1 := deployed.(*consolev1.ConsoleLink)
+	link2 := requested.(*consolev1.ConsoleLink)
+	var pairs [][2]interface{}
+	pairs = append(pairs, [2]interface{}{link1.Name, link2.Name})
+	pairs = append(pairs, [2]interface{}{link1.Labels, link2.Labels})
+	pairs = append(pairs, [2]interface{}{link1.Annotations, link2.Annotations})
+	pairs = append(pairs, [2]interface{}{link1.Spec, link2.Spec})
+	equal := EqualPairs(pairs)
+	if !equal {
+		if logger.GetSink().Enabled(1) {
+			logger.V(1).Info("Resources are not equal", "deployed", deployed, "requested", requested)
+		} else {
+			logger.Info("Resources are not equal. For more details set the Operator log level to DEBUG.")
 		}
 	}
+	return equal
+}
+
+func equalConsoleQuickStarts(deployed client.Object, requested client.Object) bool {
+	quickStart1 := deployed.(*consolev1.ConsoleQuickStart)
+	quickStart2 := requested.(*consolev1.ConsoleQuickStart)
+	var pairs [][2]interface{}
+	pairs = append(pairs, [2]interface{}{quickStart1.Name, quickStart2.Name})
+	pairs = append(pairs, [2]interface{}{quickStart1.Labels, quickStart2.Labels})
+	pairs = append(pairs, [2]interface{}{quickStart1.Annotations, quickStart2.Annotations})
+	pairs = append(pairs, [2]interface{}{quickStart1.Spec, quickStart2.Spec})
+	equal := EqualPairs(pairs)
+	if !equal {
+		if logger.GetSink().Enabled(1) {
+			logger.V(1).Info("Resources are not equal", "deployed", deployed, "requested", requested)
+		} else {
+			logger.Info("Resources are not equal. For more details set the Operator log level to DEBUG.")
+		}
+	}
+	return equal
+}
+
+// deepEquals is the comparator of kinds without a registered one, which compares their specs, or the whole objects if they have none
+func deepEquals(deployed client.Object, requested client.Object) bool {
+	spec1 := reflect.ValueOf(deployed).Elem().FieldByName("Spec")
+	spec2 := reflect.ValueOf(requested).Elem().FieldByName("Spec")
+	if spec1.IsValid() && spec2.IsValid() {
+		return Equals(spec1.Interface(), spec2.Interface())
+	}
 	return Equals(deployed, requested)
 }
 
@@ -674,11 +1202,13 @@ func EqualPairs(objects [][2]interface{}) bool {
 	return true
 }
 
+// Equals compares objects based on their semantic value rather than their representation,
+// so for example quantities of "0.5" and "500m", or nil and empty slices and maps, are deemed equal
 func Equals(deployed interface{}, requested interface{}) bool {
-	diffs := deep.Equal(deployed, requested)
-	equal := len(diffs) == 0
+	equal := semanticEquals(deployed, requested)
 	if !equal {
 		if logger.GetSink().Enabled(1) {
+			diffs := deep.Equal(deployed, requested)
 			logger.V(1).Info("Objects are not equal", "deployed", deployed, "requested", requested, "diffs", diffs)
 		} else {
 			logger.Info("Objects are not equal. For more details set the Operator log level to DEBUG.")
@@ -687,6 +1217,82 @@ func Equals(deployed interface{}, requested interface{}) bool {
 	return equal
 }
 
+func semanticEquals(deployed interface{}, requested interface{}) bool {
+	if !isSemanticallyComparable(reflect.TypeOf(deployed)) || !isSemanticallyComparable(reflect.TypeOf(requested)) {
+		//Semantic equality functions cannot be called on unexported fields, so such types are compared strictly, by their representation
+		return reflect.DeepEqual(deployed, requested)
+	}
+	return semantic.DeepEqual(deployed, requested)
+}
+
+// isSemanticallyComparable returns false if the type reaches, through an unexported field, a type that has a semantic equality function
+// or an interface that might hold one
+func isSemanticallyComparable(valueType reflect.Type) bool {
+	if valueType == nil {
+		return true
+	}
+	if comparable, ok := semanticTypes.Load(valueType); ok {
+		return comparable.(bool)
+	}
+	comparable := isSemanticallyComparableField(valueType, true, map[reflect.Type]bool{})
+	semanticTypes.Store(valueType, comparable)
+	return comparable
+}
+
+func isSemanticallyComparableField(valueType reflect.Type, exported bool, visited map[reflect.Type]bool) bool {
+	if _, ok := semantic.Equalities[valueType]; ok || valueType.Kind() == reflect.Interface {
+		return exported
+	}
+	if visited[valueType] {
+		return true
+	}
+	visited[valueType] = true
+	switch valueType.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return isSemanticallyComparableField(valueType.Elem(), exported, visited)
+	case reflect.Map:
+		return isSemanticallyComparableField(valueType.Key(), exported, visited) && isSemanticallyComparableField(valueType.Elem(), exported, visited)
+	case reflect.Struct:
+		for index := 0; index < valueType.NumField(); index++ {
+			field := valueType.Field(index)
+			if !isSemanticallyComparableField(field.Type, exported && field.IsExported(), visited) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ignoreNumericPortString treats a numeric string port, such as "8080", as equivalent to its integer counterpart,
+// which only holds for ports, as other int-or-string fields distinguish an integer from a string such as a percentage
+func ignoreNumericPortString(deployed *intstr.IntOrString, requested *intstr.IntOrString) {
+	if deployed != nil && requested != nil && deployed.String() == requested.String() {
+		*deployed = *requested
+	}
+}
+
+func ignoreNumericProbePorts(probe1 *corev1.Probe, probe2 *corev1.Probe) {
+	if probe1 != nil && probe2 != nil {
+		ignoreNumericActionPorts(probe1.HTTPGet, probe2.HTTPGet, probe1.TCPSocket, probe2.TCPSocket)
+	}
+}
+
+func ignoreNumericLifecyclePorts(handler1 *corev1.LifecycleHandler, handler2 *corev1.LifecycleHandler) {
+	if handler1 != nil && handler2 != nil {
+		ignoreNumericActionPorts(handler1.HTTPGet, handler2.HTTPGet, handler1.TCPSocket, handler2.TCPSocket)
+	}
+}
+
+// ignoreNumericActionPorts normalizes the ports of the HTTP and TCP actions shared by probes and lifecycle handlers
+func ignoreNumericActionPorts(httpGet1 *corev1.HTTPGetAction, httpGet2 *corev1.HTTPGetAction, tcpSocket1 *corev1.TCPSocketAction, tcpSocket2 *corev1.TCPSocketAction) {
+	if httpGet1 != nil && httpGet2 != nil {
+		ignoreNumericPortString(&httpGet1.Port, &httpGet2.Port)
+	}
+	if tcpSocket1 != nil && tcpSocket2 != nil {
+		ignoreNumericPortString(&tcpSocket1.Port, &tcpSocket2.Port)
+	}
+}
+
 func ignoreEmptyMaps(deployed metav1.Object, requested metav1.Object) {
 	if requested.GetAnnotations() == nil && deployed.GetAnnotations() != nil && len(deployed.GetAnnotations()) == 0 {
 		deployed.SetAnnotations(nil)
@@ -0,0 +1,252 @@
+package compare
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	fuzz "github.com/google/gofuzz"
+	oappsv1 "github.com/openshift/api/apps/v1"
+	buildv1 "github.com/openshift/api/build/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const fuzzSeeds = 50
+
+func getFuzzer(seed int64) *fuzz.Fuzzer {
+	return fuzz.NewWithSeed(seed).NilChance(0.2).NumElements(0, 3).Funcs(
+		func(quantity *resource.Quantity, c fuzz.Continue) {
+			*quantity = *resource.NewMilliQuantity(c.Int63n(100000), resource.DecimalSI)
+		},
+		func(value *intstr.IntOrString, c fuzz.Continue) {
+			if c.RandBool() {
+				*value = intstr.FromInt(c.Intn(65536))
+			} else {
+				*value = intstr.FromString(c.RandString())
+			}
+		},
+		func(extension *runtime.RawExtension, c fuzz.Continue) {
+			if c.RandBool() {
+				extension.Raw = []byte(fmt.Sprintf(`{"kind":"ConfigMap","metadata":{"name":%q}}`, c.RandString()))
+			} else {
+				extension.Raw = []byte(c.RandString())
+			}
+		},
+	)
+}
+
+// newFuzzedObject returns a randomly populated object of the given type, deterministically based on the seed
+func newFuzzedObject(resourceType reflect.Type, seed int64) client.Object {
+	object := reflect.New(resourceType).Interface().(client.Object)
+	getFuzzer(seed).Fuzz(object)
+	return object
+}
+
+func addSeeds(f *testing.F) {
+	for seed := int64(0); seed < fuzzSeeds; seed++ {
+		f.Add(seed)
+	}
+}
+
+func addSeedPairs(f *testing.F) {
+	for seed := int64(0); seed < fuzzSeeds; seed++ {
+		f.Add(seed, seed+fuzzSeeds)
+	}
+}
+
+func FuzzComparatorsAreReflexive(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, seed int64) {
+		for resourceType, comparator := range defaultMap() {
+			object := newFuzzedObject(resourceType, seed)
+			if !comparator(object, object.DeepCopyObject().(client.Object)) {
+				t.Errorf("Expected %v to be equal to its copy: %v", resourceType, object)
+			}
+		}
+	})
+}
+
+func FuzzComparatorsDoNotMutateInputs(f *testing.F) {
+	addSeedPairs(f)
+	f.Fuzz(func(t *testing.T, deployedSeed int64, requestedSeed int64) {
+		for resourceType, comparator := range defaultMap() {
+			deployed := newFuzzedObject(resourceType, deployedSeed)
+			requested := newFuzzedObject(resourceType, requestedSeed)
+			deployedCopy := deployed.DeepCopyObject()
+			requestedCopy := requested.DeepCopyObject()
+			//Objects of unrelated content, and of mismatched list lengths, must not cause a panic
+			comparator(deployed, requested)
+			if !reflect.DeepEqual(deployedCopy, deployed) {
+				t.Errorf("Expected deployed %v not to be modified", resourceType)
+			}
+			if !reflect.DeepEqual(requestedCopy, requested) {
+				t.Errorf("Expected requested %v not to be modified", resourceType)
+			}
+		}
+	})
+}
+
+func FuzzComparatorsIgnoreServerDefaults(f *testing.F) {
+	addSeeds(f)
+	defaulters := map[reflect.Type]func(client.Object){
+		reflect.TypeOf(appsv1.Deployment{}):        setDeploymentDefaults,
+		reflect.TypeOf(oappsv1.DeploymentConfig{}): setDeploymentConfigDefaults,
+		reflect.TypeOf(corev1.Service{}):           setServiceDefaults,
+		reflect.TypeOf(routev1.Route{}):            setRouteDefaults,
+		reflect.TypeOf(buildv1.BuildConfig{}):      setBuildConfigDefaults,
+	}
+	f.Fuzz(func(t *testing.T, seed int64) {
+		for resourceType, setDefaults := range defaulters {
+			requested := newFuzzedObject(resourceType, seed)
+			deployed := requested.DeepCopyObject().(client.Object)
+			setDefaults(deployed)
+			if !defaultMap()[resourceType](deployed, requested) {
+				t.Errorf("Expected %v to be equal after setting server defaults: %v", resourceType, requested)
+			}
+		}
+	})
+}
+
+// setDeploymentDefaults sets the fields of a deployment that the API server defaults, when they are not specified
+func setDeploymentDefaults(object client.Object) {
+	deployment := object.(*appsv1.Deployment)
+	if deployment.Spec.RevisionHistoryLimit == nil {
+		limit := int32(10)
+		deployment.Spec.RevisionHistoryLimit = &limit
+	}
+	if deployment.Spec.ProgressDeadlineSeconds == nil {
+		deadline := int32(600)
+		deployment.Spec.ProgressDeadlineSeconds = &deadline
+	}
+	if deployment.Spec.Strategy.RollingUpdate == nil {
+		maxSurge := intstr.FromString("25%")
+		maxUnavailable := intstr.FromString("25%")
+		deployment.Spec.Strategy.RollingUpdate = &appsv1.RollingUpdateDeployment{MaxSurge: &maxSurge, MaxUnavailable: &maxUnavailable}
+	}
+	setPodDefaults(&deployment.Spec.Template)
+}
+
+// setDeploymentConfigDefaults sets the fields of a deployment config that the API server defaults, when they are not specified
+func setDeploymentConfigDefaults(object client.Object) {
+	dc := object.(*oappsv1.DeploymentConfig)
+	if dc.Spec.RevisionHistoryLimit == nil {
+		limit := int32(10)
+		dc.Spec.RevisionHistoryLimit = &limit
+	}
+	if dc.Spec.Strategy.Type == "" && dc.Spec.Strategy.RollingParams == nil {
+		dc.Spec.Strategy.Type = oappsv1.DeploymentStrategyTypeRolling
+	}
+	if len(dc.Spec.Triggers) == 0 {
+		dc.Spec.Triggers = oappsv1.DeploymentTriggerPolicies{{Type: oappsv1.DeploymentTriggerOnConfigChange}}
+	}
+	if dc.Spec.Template != nil {
+		setPodDefaults(dc.Spec.Template)
+	}
+}
+
+// setBuildConfigDefaults sets the fields of a build config that the API server defaults, when they are not specified
+func setBuildConfigDefaults(object client.Object) {
+	bc := object.(*buildv1.BuildConfig)
+	if bc.Spec.RunPolicy == "" {
+		bc.Spec.RunPolicy = buildv1.BuildRunPolicySerial
+	}
+	for index := range bc.Spec.Triggers {
+		trigger := &bc.Spec.Triggers[index]
+		if trigger.Type == buildv1.ImageChangeBuildTriggerType && trigger.ImageChange == nil {
+			trigger.ImageChange = &buildv1.ImageChangeTrigger{LastTriggeredImageID: "registry.example.com/builder@sha256:1234"}
+		}
+	}
+}
+
+func setPodDefaults(template *corev1.PodTemplateSpec) {
+	if template.Spec.RestartPolicy == "" {
+		template.Spec.RestartPolicy = corev1.RestartPolicyAlways
+	}
+	if template.Spec.DNSPolicy == "" {
+		template.Spec.DNSPolicy = corev1.DNSClusterFirst
+	}
+	if template.Spec.SchedulerName == "" {
+		template.Spec.SchedulerName = corev1.DefaultSchedulerName
+	}
+	if template.Spec.SecurityContext == nil {
+		template.Spec.SecurityContext = &corev1.PodSecurityContext{}
+	}
+	if template.Spec.TerminationGracePeriodSeconds == nil {
+		period := int64(corev1.DefaultTerminationGracePeriodSeconds)
+		template.Spec.TerminationGracePeriodSeconds = &period
+	}
+	for _, containers := range [][]corev1.Container{template.Spec.Containers, template.Spec.InitContainers} {
+		for index := range containers {
+			container := &containers[index]
+			if container.TerminationMessagePath == "" {
+				container.TerminationMessagePath = corev1.TerminationMessagePathDefault
+			}
+			if container.TerminationMessagePolicy == "" {
+				container.TerminationMessagePolicy = corev1.TerminationMessageReadFile
+			}
+			if container.ImagePullPolicy == "" {
+				container.ImagePullPolicy = corev1.PullAlways
+			}
+			for _, probe := range []*corev1.Probe{container.LivenessProbe, container.ReadinessProbe} {
+				if probe != nil && probe.FailureThreshold == 0 {
+					probe.FailureThreshold = 3
+				}
+				if probe != nil && probe.PeriodSeconds == 0 {
+					probe.PeriodSeconds = 10
+				}
+			}
+			for _, env := range container.Env {
+				if env.ValueFrom != nil && env.ValueFrom.FieldRef != nil && env.ValueFrom.FieldRef.APIVersion == "" {
+					env.ValueFrom.FieldRef.APIVersion = "v1"
+				}
+			}
+		}
+	}
+}
+
+// setServiceDefaults sets the fields of a service that the API server defaults, when they are not specified
+func setServiceDefaults(object client.Object) {
+	service := object.(*corev1.Service)
+	if service.Spec.ClusterIP == "" {
+		service.Spec.ClusterIP = "172.30.0.1"
+	}
+	if service.Spec.Type == "" {
+		service.Spec.Type = corev1.ServiceTypeClusterIP
+	}
+	if service.Spec.SessionAffinity == "" {
+		service.Spec.SessionAffinity = corev1.ServiceAffinityNone
+	}
+	if len(service.Spec.IPFamilies) == 0 {
+		service.Spec.IPFamilies = []corev1.IPFamily{corev1.IPv4Protocol}
+	}
+	for index := range service.Spec.Ports {
+		port := &service.Spec.Ports[index]
+		if port.Protocol == "" {
+			port.Protocol = corev1.ProtocolTCP
+		}
+		if port.TargetPort == (intstr.IntOrString{}) {
+			port.TargetPort = intstr.FromInt(int(port.Port))
+		}
+	}
+}
+
+// setRouteDefaults sets the fields of a route that the API server defaults, when they are not specified
+func setRouteDefaults(object client.Object) {
+	route := object.(*routev1.Route)
+	if route.Spec.Host == "" {
+		route.Spec.Host = "generated.apps.example.com"
+	}
+	if route.Spec.WildcardPolicy == "" {
+		route.Spec.WildcardPolicy = routev1.WildcardPolicyNone
+	}
+	if route.Spec.To.Weight == nil {
+		weight := int32(100)
+		route.Spec.To.Weight = &weight
+	}
+}
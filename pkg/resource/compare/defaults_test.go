@@ -8,10 +8,22 @@ import (
 	utils "github.com/RHsyseng/operator-utils/pkg/resource/test"
 	oappsv1 "github.com/openshift/api/apps/v1"
 	obuildv1 "github.com/openshift/api/build/v1"
+	consolev1 "github.com/openshift/api/console/v1"
+	imagev1 "github.com/openshift/api/image/v1"
 	routev1 "github.com/openshift/api/route/v1"
+	templatev1 "github.com/openshift/api/template/v1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 func TestCompareRoutes(t *testing.T) {
@@ -168,6 +180,24 @@ func TestCompareBuildConfigWebHooks(t *testing.T) {
 	assert.True(t, equalBuildConfigs(&bcs[0], &bcs[1]), "Expected resources to be deemed equal based on BC comparator")
 }
 
+func TestCompareBuildConfigDefaultedTriggers(t *testing.T) {
+	bcs := utils.GetBuildConfigs(2)
+	bcs[1].Name = bcs[0].Name
+	bcs[0].Spec.Triggers = []obuildv1.BuildTriggerPolicy{
+		{Type: obuildv1.ConfigChangeBuildTriggerType},
+		{Type: obuildv1.ImageChangeBuildTriggerType, ImageChange: &obuildv1.ImageChangeTrigger{LastTriggeredImageID: "registry.example.com/builder@sha256:1234"}},
+	}
+	bcs[1].Spec.Triggers = []obuildv1.BuildTriggerPolicy{
+		{Type: obuildv1.ConfigChangeBuildTriggerType},
+		{Type: obuildv1.ImageChangeBuildTriggerType},
+	}
+	assert.True(t, equalBuildConfigs(&bcs[0], &bcs[1]), "Expected a defaulted image change trigger to be deemed equal based on BC comparator")
+
+	bcs[1].Spec.Triggers = bcs[1].Spec.Triggers[:1]
+	assert.False(t, equalBuildConfigs(&bcs[0], &bcs[1]), "Expected a removed trigger to be deemed different based on BC comparator")
+	assert.False(t, equalBuildConfigs(&bcs[1], &bcs[0]), "Expected an added trigger to be deemed different based on BC comparator")
+}
+
 func TestCompareBuildConfigEnvVars(t *testing.T) {
 	bcs := utils.GetBuildConfigs(2)
 	ordered := utils.GetEnvVars(3, true)
@@ -324,7 +354,7 @@ func TestCompareUnorderedDeploymentEnvVars(t *testing.T) {
 
 	deployments[1].Spec.Template.Spec.Containers[0].Env = unorderedVars
 
-	assert.True(t, deepEquals(&deployments[0], &deployments[1]), "Has the same EnvVars, unordered. Expected resources to be deemed equal")
+	assert.False(t, deepEquals(&deployments[0], &deployments[1]), "Has the same EnvVars, unordered. Expected the default comparator to be sensitive to the order")
 	assert.True(t, equalDeployment(&deployments[0], &deployments[1]), "Has the same EnvVars, unordered. Expected resources to be deemed equal based on Deployment comparator")
 }
 
@@ -344,6 +374,438 @@ func TestCompareUnorderedDeploymentConfigEnvVars(t *testing.T) {
 
 	deployments[1].Spec.Template.Spec.Containers[0].Env = unorderedVars
 
-	assert.True(t, deepEquals(&deployments[0], &deployments[1]), "Has the same EnvVars, unordered. Expected resources to be deemed equal")
+	assert.False(t, deepEquals(&deployments[0], &deployments[1]), "Has the same EnvVars, unordered. Expected the default comparator to be sensitive to the order")
 	assert.True(t, equalDeploymentConfigs(&deployments[0], &deployments[1]), "Has the same EnvVars, unordered. Expected resources to be deemed equal based on DeploymentConfig comparator")
 }
+
+func TestCompareReferencingDeploymentEnvVars(t *testing.T) {
+	deployments := utils.GetDeployments(2)
+	deployments[1].Name = deployments[0].Name
+	deployments[0].Spec.Template.Spec.Containers = []corev1.Container{{Name: "my-container"}}
+	deployments[1].Spec.Template.Spec.Containers = []corev1.Container{{Name: "my-container"}}
+	deployments[0].Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{
+		{Name: "HOST", Value: "localhost"},
+		{Name: "URL", Value: "http://$(HOST):8080"},
+	}
+	deployments[1].Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{
+		{Name: "HOST", Value: "localhost"},
+		{Name: "URL", Value: "http://$(HOST):8080"},
+	}
+	assert.True(t, equalDeployment(&deployments[0], &deployments[1]), "Has the same EnvVars. Expected resources to be deemed equal based on Deployment comparator")
+
+	deployments[1].Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{
+		{Name: "URL", Value: "http://$(HOST):8080"},
+		{Name: "HOST", Value: "localhost"},
+	}
+	assert.False(t, equalDeployment(&deployments[0], &deployments[1]), "Reordering referencing EnvVars changes their expansion. Expected resources to be deemed different")
+	assert.Equal(t, "URL", deployments[1].Spec.Template.Spec.Containers[0].Env[0].Name, "Expected referencing EnvVars not to be sorted")
+}
+
+func TestCompareEscapedReferenceDeploymentConfigEnvVars(t *testing.T) {
+	dcs := utils.GetDeploymentConfigs(2)
+	dcs[1].Name = dcs[0].Name
+	dcs[0].Spec.Template.Spec.Containers = []corev1.Container{{Name: "my-container"}}
+	dcs[1].Spec.Template.Spec.Containers = []corev1.Container{{Name: "my-container"}}
+	dcs[0].Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{
+		{Name: "HOST", Value: "localhost"},
+		{Name: "URL", Value: "http://$$(HOST):8080"},
+	}
+	dcs[1].Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{
+		{Name: "URL", Value: "http://$$(HOST):8080"},
+		{Name: "HOST", Value: "localhost"},
+	}
+	assert.True(t, equalDeploymentConfigs(&dcs[0], &dcs[1]), "Escaped references are not expanded. Expected resources to be deemed equal based on DC comparator")
+}
+
+func TestCompareReferencingBuildConfigEnvVars(t *testing.T) {
+	bcs := utils.GetBuildConfigs(2)
+	bcs[1].Name = bcs[0].Name
+	bcs[0].Spec.Strategy.DockerStrategy = &obuildv1.DockerBuildStrategy{Env: []corev1.EnvVar{
+		{Name: "A", Value: "a"},
+		{Name: "B", Value: "$(A)-b"},
+	}}
+	bcs[1].Spec.Strategy.DockerStrategy = &obuildv1.DockerBuildStrategy{Env: []corev1.EnvVar{
+		{Name: "B", Value: "$(A)-b"},
+		{Name: "A", Value: "a"},
+	}}
+	assert.False(t, equalBuildConfigs(&bcs[0], &bcs[1]), "Reordering referencing EnvVars changes their expansion. Expected resources to be deemed different")
+}
+
+func TestGetEnvVarReferences(t *testing.T) {
+	assert.Empty(t, getEnvVarReferences("plain value"))
+	assert.Empty(t, getEnvVarReferences("$$(ESCAPED)"))
+	assert.Empty(t, getEnvVarReferences("$(UNTERMINATED"))
+	assert.Equal(t, []string{"A", "B"}, getEnvVarReferences("$(A)/$$(C)/$(B)"))
+}
+
+func TestCompareDeploymentQuantities(t *testing.T) {
+	deployments := utils.GetDeployments(2)
+	deployments[1].Name = deployments[0].Name
+	deployments[0].Spec.Template.Spec.Containers = []corev1.Container{{
+		Name: "my-container",
+		Resources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("500m"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+		},
+	}}
+	deployments[1].Spec.Template.Spec.Containers = []corev1.Container{{
+		Name: "my-container",
+		Resources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("0.5"),
+				corev1.ResourceMemory: resource.MustParse("1073741824"),
+			},
+		},
+	}}
+	assert.True(t, equalDeployment(&deployments[0], &deployments[1]), "Canonicalized quantities should be deemed equal based on Deployment comparator")
+
+	deployments[1].Spec.Template.Spec.Containers[0].Resources.Limits[corev1.ResourceCPU] = resource.MustParse("1")
+	assert.False(t, equalDeployment(&deployments[0], &deployments[1]), "Different CPU limits should be deemed different based on Deployment comparator")
+}
+
+func TestCompareSpecsByDefault(t *testing.T) {
+	pod := func(cpu string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod"},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name:      "my-container",
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}},
+			}}},
+		}
+	}
+	assert.True(t, deepEquals(pod("500m"), pod("0.5")), "Specs that only differ in the representation of quantities should be deemed equal by default")
+	assert.False(t, deepEquals(pod("500m"), pod("1")), "Specs with different quantities should be deemed different by default")
+}
+
+func TestCompareContainerPorts(t *testing.T) {
+	deployments := utils.GetDeployments(2)
+	deployments[1].Name = deployments[0].Name
+	container := func(port intstr.IntOrString) corev1.Container {
+		return corev1.Container{
+			Name:           "my-container",
+			LivenessProbe:  &corev1.Probe{ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: "/health", Port: port}}},
+			ReadinessProbe: &corev1.Probe{ProbeHandler: corev1.ProbeHandler{TCPSocket: &corev1.TCPSocketAction{Port: port}}},
+			StartupProbe:   &corev1.Probe{ProbeHandler: corev1.ProbeHandler{TCPSocket: &corev1.TCPSocketAction{Port: port}}},
+			Lifecycle: &corev1.Lifecycle{
+				PostStart: &corev1.LifecycleHandler{HTTPGet: &corev1.HTTPGetAction{Path: "/start", Port: port}},
+				PreStop:   &corev1.LifecycleHandler{TCPSocket: &corev1.TCPSocketAction{Port: port}},
+			},
+		}
+	}
+	deployments[0].Spec.Template.Spec.Containers = []corev1.Container{container(intstr.FromInt(8080))}
+	deployments[1].Spec.Template.Spec.Containers = []corev1.Container{container(intstr.FromString("8080"))}
+	assert.True(t, equalDeployment(&deployments[0], &deployments[1]), "Numeric string probe and lifecycle ports should be deemed equal based on Deployment comparator")
+
+	deployments[1].Spec.Template.Spec.Containers = []corev1.Container{container(intstr.FromString("http"))}
+	assert.False(t, equalDeployment(&deployments[0], &deployments[1]), "Named probe and lifecycle ports should be deemed different based on Deployment comparator")
+}
+
+func TestCompareServiceTargetPorts(t *testing.T) {
+	services := utils.GetServices(2)
+	services[1].Name = services[0].Name
+	services[0].Spec.Ports = []corev1.ServicePort{{Name: "http", Port: 8080, TargetPort: intstr.FromInt(8080)}}
+	services[1].Spec.Ports = []corev1.ServicePort{{Name: "http", Port: 8080}}
+	assert.True(t, equalServices(&services[0], &services[1]), "Defaulted target port should be deemed equal based on service comparator")
+
+	services[1].Spec.Ports = []corev1.ServicePort{{Name: "http", Port: 8080, TargetPort: intstr.FromString("8080")}}
+	assert.True(t, equalServices(&services[0], &services[1]), "Numeric string target port should be deemed equal based on service comparator")
+
+	services[1].Spec.Ports = []corev1.ServicePort{{Name: "http", Port: 8080, TargetPort: intstr.FromString("http")}}
+	assert.False(t, equalServices(&services[0], &services[1]), "Named target port should be deemed different based on service comparator")
+
+	services[1].Spec.Ports = []corev1.ServicePort{{Name: "http", Port: 8080, TargetPort: intstr.FromInt(8443)}}
+	assert.False(t, equalServices(&services[0], &services[1]), "Different target port should be deemed different based on service comparator")
+}
+
+func TestEqualsSemantic(t *testing.T) {
+	assert.True(t, Equals(resource.MustParse("0.5"), resource.MustParse("500m")))
+	assert.True(t, Equals(map[string]string{}, map[string]string(nil)))
+	assert.False(t, Equals(intstr.FromInt(80), intstr.FromString("80")), "Only port fields should deem numeric strings equal to integers")
+	assert.False(t, Equals(intstr.FromString("25%"), intstr.FromInt(25)))
+	assert.True(t, EqualPairs([][2]interface{}{
+		{resource.MustParse("1Gi"), resource.MustParse("1024Mi")},
+		{[]string{}, []string(nil)},
+	}))
+}
+
+func TestCompareAggregatedClusterRoles(t *testing.T) {
+	aggregationRule := &rbacv1.AggregationRule{
+		ClusterRoleSelectors: []metav1.LabelSelector{{MatchLabels: map[string]string{"rbac.example.com/aggregate-to-monitoring": "true"}}},
+	}
+	deployed := &rbacv1.ClusterRole{
+		ObjectMeta:      metav1.ObjectMeta{Name: "monitoring"},
+		AggregationRule: aggregationRule,
+		Rules:           []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"services"}, Verbs: []string{"get", "list"}}},
+	}
+	requested := &rbacv1.ClusterRole{
+		ObjectMeta:      metav1.ObjectMeta{Name: "monitoring"},
+		AggregationRule: aggregationRule.DeepCopy(),
+	}
+	assert.True(t, equalClusterRoles(deployed, requested), "Expected aggregated rules to be ignored based on ClusterRole comparator")
+
+	requested.AggregationRule = nil
+	assert.False(t, equalClusterRoles(deployed, requested), "Expected rules to be compared based on ClusterRole comparator")
+}
+
+func TestCompareClusterRoleBindings(t *testing.T) {
+	deployed := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "binding"},
+		Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: "sa", Namespace: "ns"}},
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "role"},
+	}
+	requested := deployed.DeepCopy()
+	assert.True(t, equalClusterRoleBindings(deployed, requested), "Expected resources to be deemed equal based on ClusterRoleBinding comparator")
+
+	requested.Subjects[0].Namespace = "other"
+	assert.False(t, equalClusterRoleBindings(deployed, requested), "Expected resources to be deemed different based on ClusterRoleBinding comparator")
+
+	requested = deployed.DeepCopy()
+	requested.RoleRef.Kind = "Role"
+	assert.False(t, equalClusterRoleBindings(deployed, requested), "Expected a different roleRef kind to be deemed different based on ClusterRoleBinding comparator")
+}
+
+func TestCompareNetworkPolicies(t *testing.T) {
+	tcp := corev1.ProtocolTCP
+	port := intstr.FromInt(8080)
+	deployed := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy"},
+		Spec: networkingv1.NetworkPolicySpec{
+			Ingress:     []networkingv1.NetworkPolicyIngressRule{{Ports: []networkingv1.NetworkPolicyPort{{Protocol: &tcp, Port: &port}}}},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+	requested := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy"},
+		Spec: networkingv1.NetworkPolicySpec{
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{Ports: []networkingv1.NetworkPolicyPort{{Port: &port}}}},
+		},
+	}
+	assert.True(t, equalNetworkPolicies(deployed, requested), "Expected defaulted values to be ignored based on NetworkPolicy comparator")
+	assert.NotNil(t, deployed.Spec.Ingress[0].Ports[0].Protocol, "Expected deployed object not to be modified")
+
+	otherPort := intstr.FromInt(8443)
+	requested.Spec.Ingress[0].Ports[0].Port = &otherPort
+	assert.False(t, equalNetworkPolicies(deployed, requested), "Expected resources to be deemed different based on NetworkPolicy comparator")
+}
+
+func TestComparePodDisruptionBudgets(t *testing.T) {
+	minAvailable := intstr.FromInt(1)
+	policy := policyv1.IfHealthyBudget
+	deployed := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: "pdb"},
+		Spec:       policyv1.PodDisruptionBudgetSpec{MinAvailable: &minAvailable, UnhealthyPodEvictionPolicy: &policy},
+		Status:     policyv1.PodDisruptionBudgetStatus{CurrentHealthy: 2},
+	}
+	requestedMinAvailable := intstr.FromInt(1)
+	requested := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: "pdb"},
+		Spec:       policyv1.PodDisruptionBudgetSpec{MinAvailable: &requestedMinAvailable},
+	}
+	assert.True(t, equalPodDisruptionBudgets(deployed, requested), "Expected resources to be deemed equal based on PodDisruptionBudget comparator")
+
+	requestedMinAvailable = intstr.FromString("1")
+	assert.False(t, equalPodDisruptionBudgets(deployed, requested), "Expected a string minAvailable to be deemed different from an integer based on PodDisruptionBudget comparator")
+
+	requestedMinAvailable = intstr.FromString("50%")
+	assert.False(t, equalPodDisruptionBudgets(deployed, requested), "Expected resources to be deemed different based on PodDisruptionBudget comparator")
+}
+
+func TestCompareHorizontalPodAutoscalers(t *testing.T) {
+	minReplicas := int32(1)
+	utilization := int32(80)
+	window := int32(300)
+	deployed := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "hpa"},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "app"},
+			MinReplicas:    &minReplicas,
+			MaxReplicas:    5,
+			Metrics: []autoscalingv2.MetricSpec{{
+				Type: autoscalingv2.ResourceMetricSourceType,
+				Resource: &autoscalingv2.ResourceMetricSource{
+					Name:   corev1.ResourceCPU,
+					Target: autoscalingv2.MetricTarget{Type: autoscalingv2.UtilizationMetricType, AverageUtilization: &utilization},
+				},
+			}},
+			Behavior: &autoscalingv2.HorizontalPodAutoscalerBehavior{
+				ScaleUp:   &autoscalingv2.HPAScalingRules{Policies: []autoscalingv2.HPAScalingPolicy{{Type: autoscalingv2.PodsScalingPolicy, Value: 4, PeriodSeconds: 15}}},
+				ScaleDown: &autoscalingv2.HPAScalingRules{StabilizationWindowSeconds: &window, Policies: []autoscalingv2.HPAScalingPolicy{{Type: autoscalingv2.PercentScalingPolicy, Value: 100, PeriodSeconds: 15}}},
+			},
+		},
+	}
+	requested := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "hpa"},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "app"},
+			MaxReplicas:    5,
+			Behavior: &autoscalingv2.HorizontalPodAutoscalerBehavior{
+				ScaleDown: &autoscalingv2.HPAScalingRules{StabilizationWindowSeconds: &window},
+			},
+		},
+	}
+	assert.True(t, equalHorizontalPodAutoscalers(deployed, requested), "Expected defaulted values to be ignored based on HorizontalPodAutoscaler comparator")
+
+	requested.Spec.MaxReplicas = 10
+	assert.False(t, equalHorizontalPodAutoscalers(deployed, requested), "Expected resources to be deemed different based on HorizontalPodAutoscaler comparator")
+}
+
+func TestCompareResourceQuotas(t *testing.T) {
+	deployed := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: "ns"},
+		Spec:       corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("2000m")}},
+		Status:     corev1.ResourceQuotaStatus{Used: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("1")}},
+	}
+	requested := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: "ns"},
+		Spec:       corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("2")}},
+	}
+	assert.True(t, equalResourceQuotas(deployed, requested), "Expected resources to be deemed equal based on ResourceQuota comparator")
+
+	requested.Spec.Hard[corev1.ResourceRequestsCPU] = resource.MustParse("4")
+	assert.False(t, equalResourceQuotas(deployed, requested), "Expected resources to be deemed different based on ResourceQuota comparator")
+}
+
+func TestCompareImageStreams(t *testing.T) {
+	generation := int64(2)
+	deployed := &imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "is",
+			Namespace:   "ns",
+			Annotations: map[string]string{dockerRepositoryCheckAnnotation: "2021-01-01T00:00:00Z"},
+		},
+		Spec: imagev1.ImageStreamSpec{
+			Tags: []imagev1.TagReference{
+				{
+					Name:            "latest",
+					From:            &corev1.ObjectReference{Kind: "DockerImage", Name: "quay.io/namespace/image:latest"},
+					Generation:      &generation,
+					ReferencePolicy: imagev1.TagReferencePolicy{Type: imagev1.SourceTagReferencePolicy},
+				},
+				{
+					Name:            "1.0",
+					From:            &corev1.ObjectReference{Kind: "DockerImage", Name: "quay.io/namespace/image:1.0"},
+					Generation:      &generation,
+					ReferencePolicy: imagev1.TagReferencePolicy{Type: imagev1.SourceTagReferencePolicy},
+				},
+			},
+		},
+		Status: imagev1.ImageStreamStatus{
+			DockerImageRepository: "image-registry.openshift-image-registry.svc:5000/ns/is",
+			Tags:                  []imagev1.NamedTagEventList{{Tag: "latest"}},
+		},
+	}
+	requested := &imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Name: "is", Namespace: "ns"},
+		Spec: imagev1.ImageStreamSpec{
+			Tags: []imagev1.TagReference{
+				{Name: "1.0", From: &corev1.ObjectReference{Kind: "DockerImage", Name: "quay.io/namespace/image:1.0"}},
+				{Name: "latest", From: &corev1.ObjectReference{Kind: "DockerImage", Name: "quay.io/namespace/image:latest"}},
+			},
+		},
+	}
+	assert.True(t, equalImageStreams(deployed, requested), "Expected generated values to be ignored based on ImageStream comparator")
+	assert.Equal(t, "latest", deployed.Spec.Tags[0].Name, "Expected deployed object not to be modified")
+	assert.Equal(t, "1.0", requested.Spec.Tags[0].Name, "Expected requested object not to be modified")
+
+	requested.Spec.Tags[0].ImportPolicy.Scheduled = true
+	assert.False(t, equalImageStreams(deployed, requested), "Expected resources to be deemed different based on ImageStream comparator")
+}
+
+func TestCompareImageStreamImportPolicies(t *testing.T) {
+	deployed := &imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Name: "is", Namespace: "ns"},
+		Spec: imagev1.ImageStreamSpec{
+			Tags: []imagev1.TagReference{{
+				Name:            "latest",
+				From:            &corev1.ObjectReference{Kind: "DockerImage", Name: "quay.io/namespace/image:latest"},
+				ImportPolicy:    imagev1.TagImportPolicy{ImportMode: imagev1.ImportModeLegacy},
+				ReferencePolicy: imagev1.TagReferencePolicy{Type: imagev1.SourceTagReferencePolicy},
+			}},
+		},
+	}
+	requested := &imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Name: "is", Namespace: "ns"},
+		Spec: imagev1.ImageStreamSpec{
+			Tags: []imagev1.TagReference{{
+				Name:            "latest",
+				From:            &corev1.ObjectReference{Kind: "DockerImage", Name: "quay.io/namespace/image:latest"},
+				ReferencePolicy: imagev1.TagReferencePolicy{Type: imagev1.SourceTagReferencePolicy},
+			}},
+		},
+	}
+	assert.True(t, equalImageStreams(deployed, requested), "Expected the defaulted import mode to be ignored based on ImageStream comparator")
+	assert.Equal(t, imagev1.ImportModeLegacy, deployed.Spec.Tags[0].ImportPolicy.ImportMode, "Expected deployed object not to be modified")
+
+	requested.Spec.Tags[0].ImportPolicy.ImportMode = imagev1.ImportModePreserveOriginal
+	assert.False(t, equalImageStreams(deployed, requested), "Expected a different import mode to be deemed different based on ImageStream comparator")
+}
+
+func TestCompareTemplates(t *testing.T) {
+	deployed := &templatev1.Template{
+		ObjectMeta: metav1.ObjectMeta{Name: "template", Namespace: "ns"},
+		Objects:    []runtime.RawExtension{{Raw: []byte(`{"apiVersion":"v1","kind":"Service","metadata":{"name":"${NAME}"}}`)}},
+		Parameters: []templatev1.Parameter{{Name: "NAME", Required: true}},
+	}
+	requested := &templatev1.Template{
+		ObjectMeta: metav1.ObjectMeta{Name: "template", Namespace: "ns"},
+		Objects: []runtime.RawExtension{{Object: &corev1.Service{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+			ObjectMeta: metav1.ObjectMeta{Name: "${NAME}"},
+		}}},
+		Parameters: []templatev1.Parameter{{Name: "NAME", Required: true}},
+	}
+	assert.True(t, equalTemplates(deployed, requested), "Expected raw and typed objects to be deemed equal based on Template comparator")
+
+	deployed.Objects[0].Raw = []byte(`{"apiVersion":"v1","kind":"Service","metadata":{"name":"other"}}`)
+	assert.False(t, equalTemplates(deployed, requested), "Expected resources to be deemed different based on Template comparator")
+}
+
+func TestCompareConsoleResources(t *testing.T) {
+	sample1 := &consolev1.ConsoleYAMLSample{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample"},
+		Spec:       consolev1.ConsoleYAMLSampleSpec{Title: "Sample", YAML: "kind: Service"},
+	}
+	sample2 := sample1.DeepCopy()
+	assert.True(t, equalConsoleYAMLSamples(sample1, sample2), "Expected resources to be deemed equal based on ConsoleYAMLSample comparator")
+	sample2.Spec.YAML = "kind: Route"
+	assert.False(t, equalConsoleYAMLSamples(sample1, sample2), "Expected resources to be deemed different based on ConsoleYAMLSample comparator")
+
+	link1 := &consolev1.ConsoleLink{
+		ObjectMeta: metav1.ObjectMeta{Name: "link"},
+		Spec:       consolev1.ConsoleLinkSpec{Link: consolev1.Link{Text: "Docs", Href: "https://example.com"}, Location: consolev1.HelpMenu},
+	}
+	link2 := link1.DeepCopy()
+	assert.True(t, equalConsoleLinks(link1, link2), "Expected resources to be deemed equal based on ConsoleLink comparator")
+	link2.Spec.Href = "https://example.org"
+	assert.False(t, equalConsoleLinks(link1, link2), "Expected resources to be deemed different based on ConsoleLink comparator")
+
+	quickStart1 := &consolev1.ConsoleQuickStart{
+		ObjectMeta: metav1.ObjectMeta{Name: "quickstart"},
+		Spec:       consolev1.ConsoleQuickStartSpec{DisplayName: "Quick start", DurationMinutes: 10},
+	}
+	quickStart2 := quickStart1.DeepCopy()
+	assert.True(t, equalConsoleQuickStarts(quickStart1, quickStart2), "Expected resources to be deemed equal based on ConsoleQuickStart comparator")
+	quickStart2.Spec.DurationMinutes = 5
+	assert.False(t, equalConsoleQuickStarts(quickStart1, quickStart2), "Expected resources to be deemed different based on ConsoleQuickStart comparator")
+}
+
+func TestMismatchMetric(t *testing.T) {
+	service1 := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "service1"}, Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeNodePort}}
+	service2 := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "service1"}, Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer}}
+	mismatchCount := testutil.ToFloat64(mismatches.WithLabelValues("Service"))
+	assert.True(t, DefaultComparator().Compare(service1, service1.DeepCopy()), "Expected resources to be deemed equal")
+	assert.False(t, DefaultComparator().Compare(service1, service2), "Expected resources to differ based on service comparator")
+	assert.Equal(t, mismatchCount+1, testutil.ToFloat64(mismatches.WithLabelValues("Service")), "Expected only the mismatch to be counted")
+}
+
+func TestEqualsUnexportedFields(t *testing.T) {
+	type limits struct {
+		cpu resource.Quantity
+	}
+	assert.False(t, isSemanticallyComparable(reflect.TypeOf(limits{})))
+	assert.True(t, isSemanticallyComparable(reflect.TypeOf(corev1.ResourceRequirements{})))
+	assert.True(t, Equals(limits{cpu: resource.MustParse("1")}, limits{cpu: resource.MustParse("1")}), "Types with unexported fields should be compared by their representation")
+	assert.False(t, Equals(limits{cpu: resource.MustParse("1")}, limits{cpu: resource.MustParse("2")}), "Types with unexported fields should be compared by their representation")
+}
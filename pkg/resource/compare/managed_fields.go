@@ -0,0 +1,178 @@
+package compare
+
+import (
+	"bytes"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+	"sigs.k8s.io/structured-merge-diff/v4/value"
+)
+
+// ManagedFieldsComparator returns a comparator with the default set of comparators, that uses the managed fields of deployed objects
+// to ignore any field owned by other field managers, as long as the requested object does not specify it.
+// This allows other controllers, like an HPA or a sidecar injector, to mutate the objects without the mutation being reported as an update
+func ManagedFieldsComparator(fieldManager string) ResourceComparator {
+	return &resourceComparator{
+		defaultCompareFunc: deepEquals,
+		compareFuncMap:     defaultMap(),
+		fieldManager:       fieldManager,
+	}
+}
+
+// ignoreForeignFields returns a copy of the deployed object without the fields that are exclusively owned by other field managers,
+// and absent from the requested object. The deployed object is returned as is if it cannot be processed
+func ignoreForeignFields(deployed client.Object, requested client.Object, fieldManager string) client.Object {
+	foreignFields, err := getForeignFields(deployed, fieldManager)
+	if err != nil {
+		logger.Error(err, "Failed to parse managed fields, will compare all fields", "deployed", deployed.GetName())
+		return deployed
+	}
+	if foreignFields.Empty() {
+		return deployed
+	}
+	deployedContent, err := runtime.DefaultUnstructuredConverter.ToUnstructured(deployed)
+	if err != nil {
+		logger.Error(err, "Failed to convert deployed object, will compare all fields", "deployed", deployed.GetName())
+		return deployed
+	}
+	requestedContent, err := runtime.DefaultUnstructuredConverter.ToUnstructured(requested)
+	if err != nil {
+		logger.Error(err, "Failed to convert requested object, will compare all fields", "requested", requested.GetName())
+		return deployed
+	}
+	pruned := pruneFields(deployedContent, requestedContent, foreignFields).(map[string]interface{})
+	object := reflect.New(reflect.ValueOf(deployed).Elem().Type()).Interface().(client.Object)
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(pruned, object)
+	if err != nil {
+		logger.Error(err, "Failed to convert pruned object, will compare all fields", "deployed", deployed.GetName())
+		return deployed
+	}
+	return object
+}
+
+// getForeignFields returns the set of fields that are owned by other field managers, but not by the provided one
+func getForeignFields(object client.Object, fieldManager string) (*fieldpath.Set, error) {
+	owned := &fieldpath.Set{}
+	foreign := &fieldpath.Set{}
+	for _, entry := range object.GetManagedFields() {
+		if entry.Subresource != "" || entry.FieldsV1 == nil {
+			continue
+		}
+		fields := &fieldpath.Set{}
+		err := fields.FromJSON(bytes.NewReader(entry.FieldsV1.Raw))
+		if err != nil {
+			return nil, err
+		}
+		if entry.Manager == fieldManager {
+			owned = owned.Union(fields)
+		} else {
+			foreign = foreign.Union(fields)
+		}
+	}
+	return foreign.Difference(owned), nil
+}
+
+// pruneFields removes the fields in the provided set from the deployed content, unless they also appear in the requested content
+func pruneFields(deployed interface{}, requested interface{}, fields *fieldpath.Set) interface{} {
+	fields.Members.Iterate(func(element fieldpath.PathElement) {
+		if _, found := getChild(requested, element); !found {
+			deployed = removeChild(deployed, element)
+		}
+	})
+	fields.Children.Iterate(func(element fieldpath.PathElement) {
+		child, found := getChild(deployed, element)
+		if !found {
+			return
+		}
+		requestedChild, _ := getChild(requested, element)
+		childFields, _ := fields.Children.Get(element)
+		deployed = setChild(deployed, element, pruneFields(child, requestedChild, childFields))
+	})
+	return deployed
+}
+
+func getChild(content interface{}, element fieldpath.PathElement) (interface{}, bool) {
+	if element.FieldName != nil {
+		fieldMap, ok := content.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		child, found := fieldMap[*element.FieldName]
+		return child, found
+	}
+	list, ok := content.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	index := findListItem(list, element)
+	if index < 0 {
+		return nil, false
+	}
+	return list[index], true
+}
+
+func setChild(content interface{}, element fieldpath.PathElement, child interface{}) interface{} {
+	if element.FieldName != nil {
+		if fieldMap, ok := content.(map[string]interface{}); ok {
+			fieldMap[*element.FieldName] = child
+		}
+		return content
+	}
+	if list, ok := content.([]interface{}); ok {
+		if index := findListItem(list, element); index >= 0 {
+			list[index] = child
+		}
+	}
+	return content
+}
+
+func removeChild(content interface{}, element fieldpath.PathElement) interface{} {
+	if element.FieldName != nil {
+		if fieldMap, ok := content.(map[string]interface{}); ok {
+			delete(fieldMap, *element.FieldName)
+		}
+		return content
+	}
+	if list, ok := content.([]interface{}); ok {
+		if index := findListItem(list, element); index >= 0 {
+			return append(list[:index:index], list[index+1:]...)
+		}
+	}
+	return content
+}
+
+// findListItem returns the index of the list item selected by the path element, or -1 if no such item exists
+func findListItem(list []interface{}, element fieldpath.PathElement) int {
+	switch {
+	case element.Index != nil:
+		if *element.Index < len(list) {
+			return *element.Index
+		}
+	case element.Value != nil:
+		for index := range list {
+			if value.Equals(value.NewValueInterface(list[index]), *element.Value) {
+				return index
+			}
+		}
+	case element.Key != nil:
+		for index := range list {
+			item, ok := list[index].(map[string]interface{})
+			if ok && matchesKey(item, *element.Key) {
+				return index
+			}
+		}
+	}
+	return -1
+}
+
+func matchesKey(item map[string]interface{}, key value.FieldList) bool {
+	for _, field := range key {
+		fieldValue, found := item[field.Name]
+		if !found || !value.Equals(value.NewValueInterface(fieldValue), field.Value) {
+			return false
+		}
+	}
+	return true
+}
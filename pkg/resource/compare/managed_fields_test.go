@@ -0,0 +1,73 @@
+package compare
+
+import (
+	"testing"
+
+	utils "github.com/RHsyseng/operator-utils/pkg/resource/test"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	operatorFields = `{"f:metadata":{"f:labels":{".":{},"f:app":{}}},"f:spec":{"f:template":{"f:spec":{"f:containers":{"k:{\"name\":\"app\"}":{".":{},"f:image":{},"f:name":{}}}}}}}`
+	hpaFields      = `{"f:spec":{"f:replicas":{}}}`
+	injectorFields = `{"f:metadata":{"f:annotations":{".":{},"f:sidecar.istio.io/status":{}}},"f:spec":{"f:template":{"f:spec":{"f:containers":{"k:{\"name\":\"istio-proxy\"}":{".":{},"f:image":{},"f:name":{}}}}}}}`
+)
+
+func getManagedDeployments() (*appsv1.Deployment, *appsv1.Deployment) {
+	deployments := utils.GetDeployments(2)
+	deployments[1].Name = deployments[0].Name
+	replicas := int32(5)
+	deployed := &deployments[0]
+	deployed.Labels = map[string]string{"app": "my-app"}
+	deployed.Annotations = map[string]string{"sidecar.istio.io/status": "injected"}
+	deployed.Spec.Replicas = &replicas
+	deployed.Spec.Template.Spec.Containers = []corev1.Container{
+		{Name: "app", Image: "my-image"},
+		{Name: "istio-proxy", Image: "proxy-image"},
+	}
+	deployed.ManagedFields = []metav1.ManagedFieldsEntry{
+		{Manager: "my-operator", Operation: metav1.ManagedFieldsOperationUpdate, FieldsType: "FieldsV1", FieldsV1: &metav1.FieldsV1{Raw: []byte(operatorFields)}},
+		{Manager: "kube-controller-manager", Operation: metav1.ManagedFieldsOperationUpdate, FieldsType: "FieldsV1", FieldsV1: &metav1.FieldsV1{Raw: []byte(hpaFields)}},
+		{Manager: "istio-injector", Operation: metav1.ManagedFieldsOperationUpdate, FieldsType: "FieldsV1", FieldsV1: &metav1.FieldsV1{Raw: []byte(injectorFields)}},
+	}
+	requested := &deployments[1]
+	requested.Labels = map[string]string{"app": "my-app"}
+	requested.Spec.Template.Spec.Containers = []corev1.Container{
+		{Name: "app", Image: "my-image"},
+	}
+	return deployed, requested
+}
+
+func TestManagedFieldsComparatorIgnoresForeignFields(t *testing.T) {
+	deployed, requested := getManagedDeployments()
+	assert.False(t, DefaultComparator().Compare(deployed, requested), "Expected fields set by other managers to make resources differ")
+	assert.True(t, ManagedFieldsComparator("my-operator").Compare(deployed, requested), "Expected fields owned by other managers to be ignored")
+	assert.Len(t, deployed.Spec.Template.Spec.Containers, 2, "Expected deployed object not to be modified")
+	assert.Equal(t, int32(5), *deployed.Spec.Replicas, "Expected deployed object not to be modified")
+}
+
+func TestManagedFieldsComparatorComparesRequestedFields(t *testing.T) {
+	deployed, requested := getManagedDeployments()
+	replicas := int32(3)
+	requested.Spec.Replicas = &replicas
+	assert.False(t, ManagedFieldsComparator("my-operator").Compare(deployed, requested), "Expected requested fields to be compared, even when owned by other managers")
+}
+
+func TestManagedFieldsComparatorComparesOwnedFields(t *testing.T) {
+	deployed, requested := getManagedDeployments()
+	requested.Spec.Template.Spec.Containers[0].Image = "my-new-image"
+	assert.False(t, ManagedFieldsComparator("my-operator").Compare(deployed, requested), "Expected owned fields to be compared")
+
+	deployed, requested = getManagedDeployments()
+	requested.Labels = nil
+	assert.False(t, ManagedFieldsComparator("my-operator").Compare(deployed, requested), "Expected owned fields that are no longer requested to be compared")
+}
+
+func TestManagedFieldsComparatorWithoutManagedFields(t *testing.T) {
+	deployed, requested := getManagedDeployments()
+	deployed.ManagedFields = nil
+	assert.False(t, ManagedFieldsComparator("my-operator").Compare(deployed, requested), "Expected all fields to be compared without managed fields")
+}
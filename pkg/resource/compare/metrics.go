@@ -0,0 +1,18 @@
+package compare
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var mismatches = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "operatorutils_comparator_mismatches_total",
+		Help: "Number of deployed objects found to differ from their requested counterpart, by kind",
+	},
+	[]string{"kind"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(mismatches)
+}
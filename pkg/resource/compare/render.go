@@ -0,0 +1,293 @@
+package compare
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const diffContextLines = 3
+
+var quantityType = reflect.TypeOf(resource.Quantity{})
+
+// RenderDelta returns a unified diff of the YAML representation of the changes in the delta, against their deployed counterparts.
+// Server-populated fields like managed fields, status and resource version are removed from both sides, and the fields that an
+// updated object does not specify are removed from its deployed counterpart, as they are typically defaulted by the server and
+// ignored by the comparators, so that the diff only shows the changes that are about to be written.
+// Objects are ordered by namespace and name, added ones first and removed ones last
+func RenderDelta(delta ResourceDelta, deployed []client.Object) (string, error) {
+	var builder strings.Builder
+	for _, requested := range sortObjects(delta.Added) {
+		err := writeDiff(&builder, nil, requested)
+		if err != nil {
+			return "", err
+		}
+	}
+	for _, requested := range sortObjects(delta.Updated) {
+		err := writeDiff(&builder, findCounterpart(requested, deployed), requested)
+		if err != nil {
+			return "", err
+		}
+	}
+	for _, removed := range sortObjects(delta.Removed) {
+		err := writeDiff(&builder, removed, nil)
+		if err != nil {
+			return "", err
+		}
+	}
+	return builder.String(), nil
+}
+
+// RenderDeltas returns a unified diff of the changes in each of the deltas, as produced by MapComparator, ordered by type name
+func RenderDeltas(deltas map[reflect.Type]ResourceDelta, deployed map[reflect.Type][]client.Object) (string, error) {
+	var resourceTypes []reflect.Type
+	for resourceType := range deltas {
+		resourceTypes = append(resourceTypes, resourceType)
+	}
+	sort.Slice(resourceTypes, func(i, j int) bool {
+		return resourceTypes[i].String() < resourceTypes[j].String()
+	})
+	var builder strings.Builder
+	for _, resourceType := range resourceTypes {
+		diff, err := RenderDelta(deltas[resourceType], deployed[resourceType])
+		if err != nil {
+			return "", err
+		}
+		builder.WriteString(diff)
+	}
+	return builder.String(), nil
+}
+
+func writeDiff(builder *strings.Builder, deployed client.Object, requested client.Object) error {
+	deployedContent, err := getCleanContentOrNil(deployed)
+	if err != nil {
+		return err
+	}
+	requestedContent, err := getCleanContentOrNil(requested)
+	if err != nil {
+		return err
+	}
+	if deployedContent != nil && requestedContent != nil {
+		deployedContent = removeUnrequestedFields(deployedContent, requestedContent).(map[string]interface{})
+	}
+	deployedYaml, err := getYaml(deployedContent)
+	if err != nil {
+		return err
+	}
+	requestedYaml, err := getYaml(requestedContent)
+	if err != nil {
+		return err
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(deployedYaml),
+		B:        splitLines(requestedYaml),
+		FromFile: getDiffFileName("deployed", deployed),
+		ToFile:   getDiffFileName("requested", requested),
+		Context:  diffContextLines,
+	})
+	if err != nil {
+		return err
+	}
+	builder.WriteString(diff)
+	return nil
+}
+
+// getCleanContentOrNil returns the clean content of the object, or nil for a missing object
+func getCleanContentOrNil(object client.Object) (map[string]interface{}, error) {
+	if object == nil || reflect.ValueOf(object).IsNil() {
+		return nil, nil
+	}
+	return getCleanContent(object)
+}
+
+// getYaml returns the YAML representation of the content, or an empty string for a missing object
+func getYaml(content map[string]interface{}) (string, error) {
+	if content == nil {
+		return "", nil
+	}
+	bytes, err := yaml.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
+
+// removeUnrequestedFields returns the deployed content without the map entries that are absent from the requested content,
+// descending into lists of the same length, like GetChangedFields ignores the fields that the requested object does not specify
+func removeUnrequestedFields(deployed interface{}, requested interface{}) interface{} {
+	deployedMap, deployedIsMap := deployed.(map[string]interface{})
+	requestedMap, requestedIsMap := requested.(map[string]interface{})
+	if deployedIsMap && requestedIsMap {
+		result := make(map[string]interface{}, len(requestedMap))
+		for key, value := range deployedMap {
+			if requestedValue, found := requestedMap[key]; found {
+				result[key] = removeUnrequestedFields(value, requestedValue)
+			}
+		}
+		return result
+	}
+	deployedList, deployedIsList := deployed.([]interface{})
+	requestedList, requestedIsList := requested.([]interface{})
+	if deployedIsList && requestedIsList && len(deployedList) == len(requestedList) {
+		result := make([]interface{}, len(deployedList))
+		for index := range deployedList {
+			result[index] = removeUnrequestedFields(deployedList[index], requestedList[index])
+		}
+		return result
+	}
+	return deployed
+}
+
+// getCleanContent returns the unstructured content of the object, without the fields that are populated by the server
+func getCleanContent(object client.Object) (map[string]interface{}, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	if err != nil {
+		return nil, err
+	}
+	delete(content, "status")
+	if metadata, ok := content["metadata"].(map[string]interface{}); ok {
+		for _, field := range []string{"managedFields", "resourceVersion", "uid", "generation", "creationTimestamp", "selfLink"} {
+			delete(metadata, field)
+		}
+	}
+	return content, nil
+}
+
+// getComparableContent returns the clean content of the object, with quantities in a canonical decimal representation
+func getComparableContent(object client.Object) (map[string]interface{}, error) {
+	object = object.DeepCopyObject().(client.Object)
+	canonicalizeQuantities(reflect.ValueOf(object))
+	return getCleanContent(object)
+}
+
+// canonicalizeQuantities sets each settable quantity reachable from the value to the decimal representation of its amount
+func canonicalizeQuantities(value reflect.Value) {
+	switch value.Kind() {
+	case reflect.Pointer:
+		if !value.IsNil() {
+			canonicalizeQuantities(value.Elem())
+		}
+	case reflect.Struct:
+		if value.Type() == quantityType {
+			if value.CanSet() {
+				quantity := value.Interface().(resource.Quantity)
+				value.Set(reflect.ValueOf(*resource.NewDecimalQuantity(*quantity.AsDec(), resource.DecimalSI)))
+			}
+			return
+		}
+		for index := 0; index < value.NumField(); index++ {
+			if value.Type().Field(index).IsExported() {
+				canonicalizeQuantities(value.Field(index))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for index := 0; index < value.Len(); index++ {
+			canonicalizeQuantities(value.Index(index))
+		}
+	case reflect.Map:
+		//Map values are not addressable, so each one is canonicalized as a copy that replaces it
+		for _, key := range value.MapKeys() {
+			element := reflect.New(value.Type().Elem()).Elem()
+			element.Set(value.MapIndex(key))
+			canonicalizeQuantities(element)
+			value.SetMapIndex(key, element)
+		}
+	}
+}
+
+// GetChangedFields returns the sorted paths of the fields that the requested object sets to a different value than the deployed one,
+// such as spec.template.spec.containers[0].image, ignoring the fields it does not specify, which are typically defaulted by the server.
+// Quantities are compared by value, like the comparators do, so that for example 1Gi and 1073741824 are not reported as changed
+func GetChangedFields(deployed client.Object, requested client.Object) ([]string, error) {
+	deployedContent, err := getComparableContent(deployed)
+	if err != nil {
+		return nil, err
+	}
+	requestedContent, err := getComparableContent(requested)
+	if err != nil {
+		return nil, err
+	}
+	paths := appendChangedFields(nil, "", deployedContent, requestedContent)
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func appendChangedFields(paths []string, path string, deployed interface{}, requested interface{}) []string {
+	if requested == nil {
+		return paths
+	}
+	//A map absent from the deployed object is traversed as an empty one, to report each of the fields it adds
+	deployedMap, _ := deployed.(map[string]interface{})
+	requestedMap, requestedIsMap := requested.(map[string]interface{})
+	if requestedIsMap {
+		for key := range requestedMap {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			paths = appendChangedFields(paths, childPath, deployedMap[key], requestedMap[key])
+		}
+		return paths
+	}
+	deployedList, deployedIsList := deployed.([]interface{})
+	requestedList, requestedIsList := requested.([]interface{})
+	if deployedIsList && requestedIsList && len(deployedList) == len(requestedList) {
+		for index := range requestedList {
+			paths = appendChangedFields(paths, fmt.Sprintf("%s[%d]", path, index), deployedList[index], requestedList[index])
+		}
+		return paths
+	}
+	if !semanticEquals(deployed, requested) {
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func getDiffFileName(prefix string, object client.Object) string {
+	if object == nil || reflect.ValueOf(object).IsNil() {
+		return "/dev/null"
+	}
+	kind := object.GetObjectKind().GroupVersionKind().Kind
+	if kind == "" {
+		kind = reflect.ValueOf(object).Elem().Type().Name()
+	}
+	if object.GetNamespace() == "" {
+		return fmt.Sprintf("%s/%s/%s", prefix, kind, object.GetName())
+	}
+	return fmt.Sprintf("%s/%s/%s/%s", prefix, kind, object.GetNamespace(), object.GetName())
+}
+
+func findCounterpart(requested client.Object, deployed []client.Object) client.Object {
+	for _, candidate := range deployed {
+		if candidate.GetNamespace() == requested.GetNamespace() && candidate.GetName() == requested.GetName() {
+			return candidate
+		}
+	}
+	return nil
+}
+
+func sortObjects(objects []client.Object) []client.Object {
+	sorted := append([]client.Object(nil), objects...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].GetNamespace() != sorted[j].GetNamespace() {
+			return sorted[i].GetNamespace() < sorted[j].GetNamespace()
+		}
+		return sorted[i].GetName() < sorted[j].GetName()
+	})
+	return sorted
+}
@@ -0,0 +1,141 @@
+package compare
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestRenderDelta(t *testing.T) {
+	deployedService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "service1",
+			Namespace:       "ns",
+			ResourceVersion: "123",
+			ManagedFields:   []metav1.ManagedFieldsEntry{{Manager: "my-operator"}},
+		},
+		Spec: corev1.ServiceSpec{
+			Ports:           []corev1.ServicePort{{Name: "http", Port: 8080, Protocol: corev1.ProtocolTCP}},
+			ClusterIP:       "172.30.0.1",
+			SessionAffinity: corev1.ServiceAffinityNone,
+			Type:            corev1.ServiceTypeClusterIP,
+		},
+		Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{{IP: "127.0.0.1"}}}},
+	}
+	removedService := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "service3", Namespace: "ns"}}
+	updatedService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "service1", Namespace: "ns"},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 8443}}},
+	}
+	addedService := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "service2", Namespace: "ns"}}
+	delta := ResourceDelta{
+		Added:   []client.Object{addedService},
+		Updated: []client.Object{updatedService},
+		Removed: []client.Object{removedService},
+	}
+
+	diff, err := RenderDelta(delta, []client.Object{deployedService, removedService})
+	assert.Nil(t, err, "Expect no errors rendering delta")
+	expected := `--- /dev/null
++++ requested/Service/ns/service2
+@@ -0,0 +1,4 @@
++metadata:
++  name: service2
++  namespace: ns
++spec: {}
+--- deployed/Service/ns/service1
++++ requested/Service/ns/service1
+@@ -4,5 +4,5 @@
+ spec:
+   ports:
+   - name: http
+-    port: 8080
++    port: 8443
+     targetPort: 0
+--- deployed/Service/ns/service3
++++ /dev/null
+@@ -1,4 +0,0 @@
+-metadata:
+-  name: service3
+-  namespace: ns
+-spec: {}
+`
+	assert.Equal(t, expected, diff)
+	assert.NotContains(t, diff, "managedFields")
+	assert.NotContains(t, diff, "resourceVersion")
+	assert.NotContains(t, diff, "127.0.0.1")
+	for _, defaulted := range []string{"clusterIP", "sessionAffinity", "type", "protocol"} {
+		assert.NotContains(t, diff, defaulted, "Expected fields defaulted by the server not to show as removed")
+	}
+}
+
+func TestRenderDeltas(t *testing.T) {
+	deployed := NewMapBuilder().Add(&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "service1"}}).ResourceMap()
+	deltas := map[reflect.Type]ResourceDelta{
+		reflect.TypeOf(corev1.Service{}): {Removed: deployed[reflect.TypeOf(corev1.Service{})]},
+		reflect.TypeOf(corev1.Secret{}):  {Added: []client.Object{&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret1"}}}},
+	}
+	diff, err := RenderDeltas(deltas, deployed)
+	assert.Nil(t, err, "Expect no errors rendering deltas")
+	assert.Less(t, strings.Index(diff, "requested/Secret/secret1"), strings.Index(diff, "deployed/Service/service1"), "Expected deltas to be ordered by type")
+
+	diff, err = RenderDelta(ResourceDelta{}, nil)
+	assert.Nil(t, err, "Expect no errors rendering an empty delta")
+	assert.Empty(t, diff, "Expected no diff for an empty delta")
+}
+
+func TestGetChangedFields(t *testing.T) {
+	deployed := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "service1", Namespace: "ns", ResourceVersion: "123", Labels: map[string]string{"app": "old"}},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: "172.30.0.1",
+			Ports:     []corev1.ServicePort{{Name: "http", Port: 8080, Protocol: corev1.ProtocolTCP}},
+		},
+	}
+	requested := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "service1", Namespace: "ns", Labels: map[string]string{"app": "new"}},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 8443}}},
+	}
+
+	fields, err := GetChangedFields(deployed, requested)
+	assert.Nil(t, err, "Expect no errors finding changed fields")
+	assert.Equal(t, []string{"metadata.labels.app", "spec.ports[0].port"}, fields, "Expect fields not set in the requested object to be ignored")
+
+	fields, err = GetChangedFields(deployed, deployed.DeepCopy())
+	assert.Nil(t, err, "Expect no errors finding changed fields")
+	assert.Empty(t, fields, "Expect no changed fields for identical objects")
+}
+
+func TestGetChangedFieldsOfQuantities(t *testing.T) {
+	deployed := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "ns"},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name: "container1",
+			Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("500m"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			}},
+		}}},
+	}
+	requested := deployed.DeepCopy()
+	requested.Spec.Containers[0].Resources.Limits = corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("0.5"),
+		corev1.ResourceMemory: resource.MustParse("1073741824"),
+	}
+
+	fields, err := GetChangedFields(deployed, requested)
+	assert.Nil(t, err, "Expect no errors finding changed fields")
+	assert.Empty(t, fields, "Expect equivalent quantities not to be reported as changed")
+	assert.Equal(t, "1Gi", deployed.Spec.Containers[0].Resources.Limits.Memory().String(), "Expect the deployed object not to be modified")
+
+	requested.Spec.Containers[0].Resources.Limits[corev1.ResourceMemory] = resource.MustParse("2Gi")
+	fields, err = GetChangedFields(deployed, requested)
+	assert.Nil(t, err, "Expect no errors finding changed fields")
+	assert.Equal(t, []string{"spec.containers[0].resources.limits.memory"}, fields, "Expect a different quantity to be reported as changed")
+}
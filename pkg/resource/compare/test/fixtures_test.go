@@ -0,0 +1,16 @@
+package test
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/RHsyseng/operator-utils/pkg/resource/compare"
+	"github.com/RHsyseng/operator-utils/pkg/test"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+var updateGolden = flag.Bool("update-golden", false, "overwrite the expected comparator fixture results with the actual ones")
+
+func TestComparatorFixtures(t *testing.T) {
+	test.RunComparatorFixtures(t, "testdata/comparators", scheme.Scheme, compare.DefaultComparator(), *updateGolden)
+}
@@ -0,0 +1,57 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/RHsyseng/operator-utils/pkg/resource/compare"
+	"github.com/RHsyseng/operator-utils/pkg/resource/test"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRegisterTypedComparator(t *testing.T) {
+	configMap1 := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "config"}, Data: map[string]string{"key": "value1"}}
+	configMap2 := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "config"}, Data: map[string]string{"key": "value2"}}
+
+	comparator := compare.SimpleComparator()
+	assert.False(t, comparator.Compare(configMap1, configMap2), "Expected resources to differ based on simple comparator")
+	compare.Register(comparator, func(deployed *corev1.ConfigMap, requested *corev1.ConfigMap) bool {
+		return deployed.Name == requested.Name
+	})
+	assert.True(t, comparator.Compare(configMap1, configMap2), "Expected resources to be deemed equal based on registered typed comparator")
+}
+
+func TestCompareTyped(t *testing.T) {
+	deployed := test.GetServices(3)
+	requested := test.GetServices(3)
+	requested[1].Spec.ClusterIP = "127.0.0.2"
+	requested[2].Name = "added"
+
+	delta := compare.CompareTyped(compare.DefaultComparator(),
+		[]*corev1.Service{&deployed[0], &deployed[1], &deployed[2]},
+		[]*corev1.Service{&requested[0], &requested[1], &requested[2]})
+	assert.True(t, delta.HasChanges(), "Expected changes to be found")
+	assert.Equal(t, []*corev1.Service{&requested[2]}, delta.Added)
+	assert.Equal(t, []*corev1.Service{&requested[1]}, delta.Updated)
+	assert.Equal(t, []*corev1.Service{&deployed[2]}, delta.Removed)
+
+	untyped := delta.Untyped()
+	assert.Len(t, untyped.Added, 1, "Expected the untyped delta to hold the same objects")
+	assert.Len(t, untyped.Updated, 1, "Expected the untyped delta to hold the same objects")
+	assert.Len(t, untyped.Removed, 1, "Expected the untyped delta to hold the same objects")
+}
+
+func TestGetTypedDelta(t *testing.T) {
+	deployed := test.GetServices(2)
+	requested := test.GetServices(1)
+	comparator := compare.NewMapComparator()
+	deltas := comparator.Compare(compare.NewMapBuilder().Add(&deployed[0], &deployed[1]).ResourceMap(), compare.NewMapBuilder().Add(&requested[0]).ResourceMap())
+
+	delta := compare.GetTypedDelta[corev1.Service](deltas)
+	assert.Empty(t, delta.Added, "Expected no services to be added")
+	assert.Empty(t, delta.Updated, "Expected no services to be updated")
+	assert.Equal(t, []*corev1.Service{&deployed[1]}, delta.Removed)
+	podDelta := compare.GetTypedDelta[corev1.Pod](deltas)
+	assert.False(t, podDelta.HasChanges(), "Expected no changes for types absent from the deltas")
+}
@@ -0,0 +1,87 @@
+package compare
+
+import (
+	"reflect"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TypedResourceDelta is the counterpart of ResourceDelta for a single known type, where T is a pointer type such as *corev1.Service
+type TypedResourceDelta[T client.Object] struct {
+	Added   []T
+	Updated []T
+	Removed []T
+}
+
+func (delta *TypedResourceDelta[T]) HasChanges() bool {
+	return len(delta.Added) > 0 || len(delta.Updated) > 0 || len(delta.Removed) > 0
+}
+
+// Untyped returns the delta as a ResourceDelta, as accepted by the resource writer
+func (delta *TypedResourceDelta[T]) Untyped() ResourceDelta {
+	return ResourceDelta{
+		Added:   ToObjects(delta.Added),
+		Updated: ToObjects(delta.Updated),
+		Removed: ToObjects(delta.Removed),
+	}
+}
+
+// Register sets a comparator for objects of type T, without the need to cast its arguments
+// for example: compare.Register(comparator, func(deployed, requested *corev1.Service) bool { ... })
+func Register[T any, PT interface {
+	*T
+	client.Object
+}](comparator ResourceComparator, compareFunc func(deployed *T, requested *T) bool) {
+	comparator.SetComparator(reflect.TypeOf(*new(T)), func(deployed client.Object, requested client.Object) bool {
+		return compareFunc(deployed.(PT), requested.(PT))
+	})
+}
+
+// CompareTyped compares the deployed and requested objects of type T with the provided comparator, and returns the typed delta
+func CompareTyped[T any, PT interface {
+	*T
+	client.Object
+}](comparator ResourceComparator, deployed []PT, requested []PT) TypedResourceDelta[PT] {
+	delta := comparator.CompareArrays(ToObjects(deployed), ToObjects(requested))
+	return TypedResourceDelta[PT]{
+		Added:   fromObjects[PT](delta.Added),
+		Updated: fromObjects[PT](delta.Updated),
+		Removed: fromObjects[PT](delta.Removed),
+	}
+}
+
+// GetTypedDelta returns the delta for objects of type T, from the result of MapComparator
+func GetTypedDelta[T any, PT interface {
+	*T
+	client.Object
+}](deltas map[reflect.Type]ResourceDelta) TypedResourceDelta[PT] {
+	delta := deltas[reflect.TypeOf(*new(T))]
+	return TypedResourceDelta[PT]{
+		Added:   fromObjects[PT](delta.Added),
+		Updated: fromObjects[PT](delta.Updated),
+		Removed: fromObjects[PT](delta.Removed),
+	}
+}
+
+// ToObjects converts a slice of typed objects, to a slice of client.Object as used throughout this library
+func ToObjects[T client.Object](objects []T) []client.Object {
+	if objects == nil {
+		return nil
+	}
+	converted := make([]client.Object, len(objects))
+	for index := range objects {
+		converted[index] = objects[index]
+	}
+	return converted
+}
+
+func fromObjects[T client.Object](objects []client.Object) []T {
+	if objects == nil {
+		return nil
+	}
+	converted := make([]T, len(objects))
+	for index := range objects {
+		converted[index] = objects[index].(T)
+	}
+	return converted
+}
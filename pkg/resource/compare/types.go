@@ -35,14 +35,14 @@ type ResourceComparator interface {
 
 func DefaultComparator() ResourceComparator {
 	return &resourceComparator{
-		deepEquals,
-		defaultMap(),
+		defaultCompareFunc: deepEquals,
+		compareFuncMap:     defaultMap(),
 	}
 }
 
 func SimpleComparator() ResourceComparator {
 	return &resourceComparator{
-		deepEquals,
-		make(map[reflect.Type]func(client.Object, client.Object) bool),
+		defaultCompareFunc: deepEquals,
+		compareFuncMap:     make(map[reflect.Type]func(client.Object, client.Object) bool),
 	}
 }
@@ -0,0 +1,211 @@
+package detector
+
+import (
+	"sync"
+
+	"github.com/RHsyseng/operator-utils/internal/platform"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+)
+
+// platformKey is the key under which the detection of the platform is backed off, which no group version can have
+const platformKey = "platform:"
+
+// PlatformInfo describes the platform of the cluster, its Kubernetes version and its operating system
+type PlatformInfo = platform.PlatformInfo
+
+// PlatformType is the name of the platform of the cluster, either PlatformOpenShift or PlatformKubernetes
+type PlatformType = platform.PlatformType
+
+const (
+	PlatformOpenShift  = platform.OpenShift
+	PlatformKubernetes = platform.Kubernetes
+)
+
+var (
+	routeKind                 = schema.GroupKind{Group: "route.openshift.io", Kind: "Route"}
+	serviceMonitorKind        = schema.GroupKind{Group: "monitoring.coreos.com", Kind: "ServiceMonitor"}
+	consolePluginKind         = schema.GroupKind{Group: "console.openshift.io", Kind: "ConsolePlugin"}
+	clusterServiceVersionKind = schema.GroupKind{Group: "operators.coreos.com", Kind: "ClusterServiceVersion"}
+	certificateKind           = schema.GroupKind{Group: "cert-manager.io", Kind: "Certificate"}
+
+	// wellKnownKinds are the kinds that a detector looks for, once capabilities are set
+	wellKnownKinds = []schema.GroupVersionKind{
+		routeKind.WithVersion("v1"),
+		serviceMonitorKind.WithVersion("v1"),
+		consolePluginKind.WithVersion("v1"),
+		consolePluginKind.WithVersion("v1alpha1"),
+		clusterServiceVersionKind.WithVersion("v1alpha1"),
+		certificateKind.WithVersion("v1"),
+	}
+)
+
+// Capabilities describes the APIs served by the cluster, as populated by a detector, so that reconcilers can query them
+// without calling the API server. Capabilities can also be constructed directly, for example in tests:
+// detector.NewCapabilities().WithPlatformInfo(detector.PlatformInfo{Name: detector.PlatformOpenShift, K8SVersion: "1.26"}).WithKinds(gvk)
+type Capabilities struct {
+	mutex             sync.RWMutex
+	kinds             map[schema.GroupVersionKind]bool
+	preferredVersions map[string]string
+	platformInfo      *PlatformInfo
+}
+
+// NewCapabilities creates capabilities where no kinds are served, to be populated by a detector or with the With functions
+func NewCapabilities() *Capabilities {
+	return &Capabilities{kinds: map[schema.GroupVersionKind]bool{}, preferredVersions: map[string]string{}}
+}
+
+// WithKinds records that the cluster serves the given kinds
+func (c *Capabilities) WithKinds(gvks ...schema.GroupVersionKind) *Capabilities {
+	for _, gvk := range gvks {
+		c.setKind(gvk, true)
+	}
+	return c
+}
+
+// WithPreferredVersion records the version of the group that the cluster prefers
+func (c *Capabilities) WithPreferredVersion(group string, version string) *Capabilities {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.preferredVersions[group] = version
+	return c
+}
+
+// WithPlatformInfo records the platform of the cluster
+func (c *Capabilities) WithPlatformInfo(info PlatformInfo) *Capabilities {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.platformInfo = &info
+	return c
+}
+
+// HasKind returns true if the cluster serves the kind at the given group and version
+// a detector only populates the kinds it looks for, which are Routes, ServiceMonitors, ConsolePlugins, ClusterServiceVersions and Certificates
+func (c *Capabilities) HasKind(gvk schema.GroupVersionKind) bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.kinds[gvk]
+}
+
+// HasGroupKind returns true if the cluster serves the kind at any version of the group
+func (c *Capabilities) HasGroupKind(groupKind schema.GroupKind) bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	for gvk, served := range c.kinds {
+		if served && gvk.GroupKind() == groupKind {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRoutes returns true if the cluster serves OpenShift Routes
+func (c *Capabilities) HasRoutes() bool {
+	return c.HasGroupKind(routeKind)
+}
+
+// HasServiceMonitors returns true if the cluster serves ServiceMonitors of the Prometheus operator
+func (c *Capabilities) HasServiceMonitors() bool {
+	return c.HasGroupKind(serviceMonitorKind)
+}
+
+// HasConsolePlugins returns true if the cluster serves OpenShift ConsolePlugins
+func (c *Capabilities) HasConsolePlugins() bool {
+	return c.HasGroupKind(consolePluginKind)
+}
+
+// HasOLM returns true if the cluster serves ClusterServiceVersions of the Operator Lifecycle Manager
+func (c *Capabilities) HasOLM() bool {
+	return c.HasGroupKind(clusterServiceVersionKind)
+}
+
+// HasCertManager returns true if the cluster serves Certificates of cert-manager
+func (c *Capabilities) HasCertManager() bool {
+	return c.HasGroupKind(certificateKind)
+}
+
+// PreferredVersion returns the version of the group that the cluster prefers, or an empty string if the group is not served
+func (c *Capabilities) PreferredVersion(group string) string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.preferredVersions[group]
+}
+
+// PlatformInfo returns the platform of the cluster, and false if it is not known yet
+func (c *Capabilities) PlatformInfo() (PlatformInfo, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	if c.platformInfo == nil {
+		return PlatformInfo{}, false
+	}
+	return *c.platformInfo, true
+}
+
+// IsOpenShift returns true if the platform of the cluster is known to be OpenShift
+func (c *Capabilities) IsOpenShift() bool {
+	info, known := c.PlatformInfo()
+	return known && info.IsOpenShift()
+}
+
+func (c *Capabilities) setKind(gvk schema.GroupVersionKind, served bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.kinds[gvk] = served
+}
+
+func (c *Capabilities) setPreferredVersions(groups *metav1.APIGroupList) {
+	preferredVersions := make(map[string]string, len(groups.Groups))
+	for _, group := range groups.Groups {
+		preferredVersions[group.Name] = group.PreferredVersion.Version
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.preferredVersions = preferredVersions
+}
+
+// WithCapabilities sets the capabilities that the detector populates on each scan, with the presence of well-known kinds,
+// the preferred version of each group and, once known, the platform of the cluster
+func (d *Detector) WithCapabilities(capabilities *Capabilities) *Detector {
+	d.mutex.Lock()
+	d.capabilities = capabilities
+	d.mutex.Unlock()
+	for _, gvk := range wellKnownKinds {
+		gvk := gvk
+		crd := &metav1.PartialObjectMetadata{}
+		crd.SetGroupVersionKind(gvk)
+		d.AddCRDTrigger(crd, func(runtime.Object) {
+			capabilities.setKind(gvk, true)
+		})
+		d.AddCRDRemovedTrigger(crd, func(runtime.Object) {
+			capabilities.setKind(gvk, false)
+		})
+	}
+	return d
+}
+
+// detectCapabilities populates the preferred versions and the platform of the capabilities, if set
+func (d *Detector) detectCapabilities(discovered *discoveryScan) {
+	d.mutex.Lock()
+	capabilities := d.capabilities
+	d.mutex.Unlock()
+	if capabilities == nil {
+		return
+	}
+	groups, ok := discovered.serverGroups()
+	if !ok {
+		return
+	}
+	capabilities.setPreferredVersions(groups)
+	if _, known := capabilities.PlatformInfo(); !known && discovered.request(platformKey) {
+		//the config is only used to create a discovery client, when none is provided
+		info, err := platform.K8SBasedPlatformVersioner{}.GetPlatformInfo(d.dc, &rest.Config{})
+		if err != nil {
+			discovered.failPlatform(err)
+			return
+		}
+		discovered.succeed(platformKey)
+		capabilities.WithPlatformInfo(info)
+	}
+}
@@ -0,0 +1,99 @@
+package detector
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/RHsyseng/operator-utils/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+func TestCapabilitiesConstructedDirectly(t *testing.T) {
+	capabilities := NewCapabilities().
+		WithKinds(schema.GroupVersionKind{Group: "route.openshift.io", Version: "v1", Kind: "Route"}).
+		WithPreferredVersion("route.openshift.io", "v1").
+		WithPlatformInfo(PlatformInfo{Name: PlatformOpenShift, K8SVersion: "1.26"})
+
+	assert.True(t, capabilities.HasRoutes(), "Expect Routes to be served")
+	assert.False(t, capabilities.HasServiceMonitors(), "Expect ServiceMonitors not to be served")
+	assert.Equal(t, "v1", capabilities.PreferredVersion("route.openshift.io"))
+	assert.Empty(t, capabilities.PreferredVersion("monitoring.coreos.com"), "Expect no version for a group that is not served")
+	assert.True(t, capabilities.IsOpenShift(), "Expect the platform to be OpenShift")
+	assert.False(t, NewCapabilities().IsOpenShift(), "Expect an unknown platform not to be OpenShift")
+}
+
+func TestDetectorPopulatesCapabilities(t *testing.T) {
+	dc := test.NewFakeDiscoveryBuilder().
+		WithKinds(routeKind.WithVersion("v1"), consolePluginKind.WithVersion("v1alpha1"), serviceMonitorKind.WithVersion("v1")).
+		Build()
+	d, err := NewAutoDetect(dc)
+	assert.Nil(t, err, "Expect no errors creating detector")
+	capabilities := NewCapabilities()
+	d.WithCapabilities(capabilities)
+
+	assert.Nil(t, d.ScanNow(), "Expect no errors scanning")
+	assert.True(t, capabilities.HasRoutes(), "Expect Routes to be detected")
+	assert.True(t, capabilities.HasConsolePlugins(), "Expect ConsolePlugins to be detected at an older version")
+	assert.True(t, capabilities.HasServiceMonitors(), "Expect ServiceMonitors to be detected")
+	assert.False(t, capabilities.HasOLM(), "Expect OLM not to be detected")
+	assert.False(t, capabilities.HasCertManager(), "Expect cert-manager not to be detected")
+	assert.Equal(t, "v1alpha1", capabilities.PreferredVersion("console.openshift.io"))
+	assert.True(t, capabilities.IsOpenShift(), "Expect the platform to be detected as OpenShift")
+
+	dc.Uninstall(serviceMonitorKind.WithVersion("v1"))
+	assert.Nil(t, d.ScanNow(), "Expect no errors scanning")
+	assert.False(t, capabilities.HasServiceMonitors(), "Expect ServiceMonitors to be removed")
+	assert.Empty(t, capabilities.PreferredVersion("monitoring.coreos.com"), "Expect the removed group to have no preferred version")
+}
+
+// versionlessDiscovery fails to report the version of the server, and counts the requests for it
+type versionlessDiscovery struct {
+	*test.FakeDiscovery
+	err      error
+	requests int
+}
+
+func (v *versionlessDiscovery) ServerVersion() (*version.Info, error) {
+	v.requests++
+	if v.err != nil {
+		return nil, v.err
+	}
+	return v.FakeDiscovery.ServerVersion()
+}
+
+func TestDetectorBacksOffPlatformDetection(t *testing.T) {
+	dc := &versionlessDiscovery{
+		FakeDiscovery: test.NewFakeDiscoveryBuilder().WithKinds(routeKind.WithVersion("v1")).WithServerVersion("1", "26").Build(),
+		err:           fmt.Errorf("the server is currently unable to handle the request"),
+	}
+	d, err := NewAutoDetect(dc)
+	assert.Nil(t, err, "Expect no errors creating detector")
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	d.WithClock(fakeClock).WithBackoff(time.Second, 4*time.Second)
+	var reported []schema.GroupVersion
+	d.WithErrorHandler(func(groupVersion schema.GroupVersion, err error) {
+		reported = append(reported, groupVersion)
+	})
+	capabilities := NewCapabilities()
+	d.WithCapabilities(capabilities)
+
+	assert.NotNil(t, d.ScanNow(), "Expect the scan to return the platform error")
+	assert.True(t, capabilities.HasRoutes(), "Expect a failing platform detection not to prevent the detection of kinds")
+	_, known := capabilities.PlatformInfo()
+	assert.False(t, known, "Expect the platform to be unknown")
+	assert.Empty(t, reported, "Expect the platform error not to be reported as a discovery error of a group version")
+	assert.Nil(t, d.ScanNow(), "Expect no errors while the platform detection backs off")
+	assert.Equal(t, 1, dc.requests, "Expect the platform not to be detected again before its backoff")
+
+	dc.err = nil
+	fakeClock.Step(time.Second)
+	assert.Nil(t, d.ScanNow(), "Expect no errors once the platform is detected")
+	info, known := capabilities.PlatformInfo()
+	assert.True(t, known, "Expect the platform to be detected after its backoff")
+	assert.Equal(t, "1.26", info.K8SVersion)
+	assert.True(t, capabilities.IsOpenShift(), "Expect the platform to be detected as OpenShift")
+}
@@ -0,0 +1,281 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// DynamicController watches a kind that may not be installed when the operator starts, from the moment that the detector finds
+// its CRD, and stops watching it when the CRD is removed. Each time the CRD appears, the watches are served by a new cache,
+// which is stopped along with the watches when the CRD is removed, since the cache of the manager cannot stop watching a kind.
+// It runs as a runnable of the manager, so that watches only start in the leader once it is elected, and stop with the manager
+type DynamicController struct {
+	mgr          manager.Manager
+	detector     *Detector
+	name         string
+	forType      client.Object
+	ownsTypes    []client.Object
+	options      controller.Options
+	cacheOptions cache.Options
+	newCache     cache.NewCacheFunc
+	setup        func(ctx context.Context, kindCache cache.Cache) error
+	ctx          context.Context
+	detected     bool
+	cancel       context.CancelFunc
+	running      sync.WaitGroup
+	mutex        sync.Mutex
+}
+
+// NewDynamicController creates a builder for watches that start and stop as the detector finds the CRD of the For type
+func NewDynamicController(mgr manager.Manager, detector *Detector) *DynamicController {
+	return &DynamicController{mgr: mgr, detector: detector, newCache: cache.New}
+}
+
+// Named sets the name of the controller, which defaults to the lowercase kind of the For type
+func (b *DynamicController) Named(name string) *DynamicController {
+	b.name = name
+	return b
+}
+
+// For sets the type of the custom resource that is watched and reconciled, and whose CRD the detector looks for
+func (b *DynamicController) For(object client.Object) *DynamicController {
+	b.forType = object
+	return b
+}
+
+// Owns adds a type of object that is created by the reconciler, and whose changes are reconciled as changes to their controller
+func (b *DynamicController) Owns(object client.Object) *DynamicController {
+	b.ownsTypes = append(b.ownsTypes, object)
+	return b
+}
+
+// WithOptions sets the options of the controller, the Reconciler of which is set by Complete
+func (b *DynamicController) WithOptions(options controller.Options) *DynamicController {
+	b.options = options
+	return b
+}
+
+// WithCacheOptions sets the options of the cache serving the watches, for example to restrict it to a namespace
+// the scheme and REST mapper default to those of the manager
+func (b *DynamicController) WithCacheOptions(cacheOptions cache.Options) *DynamicController {
+	b.cacheOptions = cacheOptions
+	return b
+}
+
+// WithNewCache sets the function that creates the cache serving the watches, which defaults to cache.New
+func (b *DynamicController) WithNewCache(newCache cache.NewCacheFunc) *DynamicController {
+	b.newCache = newCache
+	return b
+}
+
+// Complete registers the detector triggers that start a new controller with the reconciler, when the CRD of the For type is found,
+// and stop it when the CRD is removed. The For type is reconciled on its own changes, and the Owns types on changes to their owner
+func (b *DynamicController) Complete(reconciler reconcile.Reconciler) error {
+	options := b.options
+	options.Reconciler = reconciler
+	return b.register(func(ctx context.Context, kindCache cache.Cache) error {
+		c, err := controller.NewUnmanaged(b.getName(), b.mgr, options)
+		if err != nil {
+			return err
+		}
+		err = c.Watch(source.NewKindWithCache(b.forType, kindCache), &handler.EnqueueRequestForObject{})
+		if err != nil {
+			return err
+		}
+		for _, ownsType := range b.ownsTypes {
+			err = c.Watch(source.NewKindWithCache(ownsType, kindCache), &handler.EnqueueRequestForOwner{OwnerType: b.forType, IsController: true})
+			if err != nil {
+				return err
+			}
+		}
+		b.running.Add(1)
+		go func() {
+			defer b.running.Done()
+			if err := c.Start(ctx); err != nil {
+				logger.Error(err, "Controller stopped with an error", "controller", b.getName())
+			}
+		}()
+		return nil
+	})
+}
+
+// CompleteWithController adds a watch of the For type to an existing controller, which receives events once the CRD of the For type
+// is found, and until the CRD is removed. Owns types are not supported, as the owner type is unknown
+func (b *DynamicController) CompleteWithController(c controller.Controller, eventHandler handler.EventHandler) error {
+	if len(b.ownsTypes) > 0 {
+		return fmt.Errorf("owned types cannot be watched through an existing controller")
+	}
+	//The controller watches a single source, which is fed by the cache of each appearance of the CRD, so that watches do not pile up
+	kindSource := &dynamicSource{forType: b.forType}
+	err := b.register(kindSource.setCache)
+	if err != nil {
+		return err
+	}
+	return c.Watch(kindSource, eventHandler)
+}
+
+// Start starts watching the For type as soon as its CRD is detected, and blocks until the context is cancelled,
+// which stops the watches, and until all the watches that were started have stopped
+func (b *DynamicController) Start(ctx context.Context) error {
+	b.mutex.Lock()
+	b.ctx = ctx
+	if b.detected {
+		if err := b.start(); err != nil {
+			logger.Error(err, "Failed to start watching a detected CRD", "controller", b.getName())
+		}
+	}
+	b.mutex.Unlock()
+	<-ctx.Done()
+	b.mutex.Lock()
+	b.stop()
+	b.mutex.Unlock()
+	b.running.Wait()
+	return nil
+}
+
+// NeedLeaderElection returns true, so that the For type is only reconciled by the leader, like with the controllers of the manager
+func (b *DynamicController) NeedLeaderElection() bool {
+	return true
+}
+
+// register adds the triggers that run the setup function with a new cache when the CRD is found, and stop the cache when it is removed,
+// and adds the DynamicController to the manager, which provides the context of the watches
+func (b *DynamicController) register(setup func(ctx context.Context, kindCache cache.Cache) error) error {
+	if b.setup != nil {
+		return fmt.Errorf("the dynamic controller is already registered")
+	}
+	if b.forType == nil {
+		return fmt.Errorf("must provide an object for the reconciliation type")
+	}
+	gvk, err := apiutil.GVKForObject(b.forType, b.mgr.GetScheme())
+	if err != nil {
+		return err
+	}
+	b.setup = setup
+	crd := &metav1.PartialObjectMetadata{}
+	crd.SetGroupVersionKind(gvk)
+	b.detector.AddCRDTrigger(crd, func(runtime.Object) {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+		b.detected = true
+		if err := b.start(); err != nil {
+			logger.Error(err, "Failed to start watching a detected CRD", "controller", b.getName(), "gvk", gvk.String())
+		}
+	})
+	b.detector.AddCRDRemovedTrigger(crd, func(runtime.Object) {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+		b.detected = false
+		b.stop()
+	})
+	return b.mgr.Add(b)
+}
+
+// start creates the cache and runs the setup function, unless the watches are already running or the manager has not started
+// the DynamicController yet, and must be called while holding the mutex
+func (b *DynamicController) start() error {
+	if b.cancel != nil || b.ctx == nil || b.ctx.Err() != nil {
+		return nil
+	}
+	cacheOptions := b.cacheOptions
+	if cacheOptions.Scheme == nil {
+		cacheOptions.Scheme = b.mgr.GetScheme()
+	}
+	if cacheOptions.Mapper == nil {
+		cacheOptions.Mapper = b.mgr.GetRESTMapper()
+	}
+	kindCache, err := b.newCache(b.mgr.GetConfig(), cacheOptions)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(b.ctx)
+	b.running.Add(1)
+	go func() {
+		defer b.running.Done()
+		if err := kindCache.Start(ctx); err != nil {
+			logger.Error(err, "Cache stopped with an error", "controller", b.getName())
+		}
+	}()
+	b.cancel = cancel
+	if err = b.setup(ctx, kindCache); err != nil {
+		b.stop()
+		return err
+	}
+	return nil
+}
+
+// stop cancels the watches, which stop in the background, so that the detector triggers are not held up by running reconciliations,
+// while Start waits for them to stop on shutdown. It must be called while holding the mutex
+func (b *DynamicController) stop() {
+	if b.cancel != nil {
+		b.cancel()
+		b.cancel = nil
+	}
+}
+
+func (b *DynamicController) getName() string {
+	if b.name != "" {
+		return b.name
+	}
+	gvk, err := apiutil.GVKForObject(b.forType, b.mgr.GetScheme())
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(gvk.Kind)
+}
+
+// dynamicSource is a source of events for the For type that is watched once by an existing controller,
+// and which is fed by the cache of each appearance of its CRD
+type dynamicSource struct {
+	forType      client.Object
+	eventHandler handler.EventHandler
+	queue        workqueue.RateLimitingInterface
+	predicates   []predicate.Predicate
+	ctx          context.Context
+	kindCache    cache.Cache
+	mutex        sync.Mutex
+}
+
+// Start is called by the controller when it starts watching the source, and starts receiving events from the current cache, if any
+func (s *dynamicSource) Start(_ context.Context, eventHandler handler.EventHandler, queue workqueue.RateLimitingInterface, predicates ...predicate.Predicate) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.eventHandler = eventHandler
+	s.queue = queue
+	s.predicates = predicates
+	if s.kindCache == nil || s.ctx.Err() != nil {
+		return nil
+	}
+	return source.NewKindWithCache(s.forType, s.kindCache).Start(s.ctx, eventHandler, queue, predicates...)
+}
+
+// setCache starts receiving events from the cache of a new appearance of the CRD, until the context is cancelled
+func (s *dynamicSource) setCache(ctx context.Context, kindCache cache.Cache) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.ctx = ctx
+	s.kindCache = kindCache
+	if s.queue == nil {
+		return nil
+	}
+	return source.NewKindWithCache(s.forType, kindCache).Start(ctx, s.eventHandler, s.queue, s.predicates...)
+}
+
+func (s *dynamicSource) String() string {
+	return fmt.Sprintf("dynamic source for %T", s.forType)
+}
@@ -0,0 +1,310 @@
+package detector
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RHsyseng/operator-utils/pkg/test"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var deploymentGVK = appsv1.SchemeGroupVersion.WithKind("Deployment")
+
+func TestDynamicControllerFollowsCRD(t *testing.T) {
+	mgr := newTestManager(t)
+	dc := test.NewFakeDiscoveryBuilder().Build()
+	d, err := NewAutoDetect(dc)
+	assert.Nil(t, err, "Expect no errors creating detector")
+
+	caches := &fakeCaches{}
+	requests := make(chan reconcile.Request, 100)
+	err = NewDynamicController(mgr, d).
+		For(&appsv1.Deployment{}).
+		WithNewCache(caches.newCache).
+		Complete(reconcile.Func(func(_ context.Context, request reconcile.Request) (reconcile.Result, error) {
+			requests <- request
+			return reconcile.Result{}, nil
+		}))
+	assert.Nil(t, err, "Expect no errors registering dynamic controller")
+
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "deployment", Namespace: "namespace"}}
+	reconciles := func(informer *lockedInformer) bool {
+		informer.Add(deployment)
+		select {
+		case request := <-requests:
+			return request.Name == deployment.Name
+		case <-time.After(10 * time.Millisecond):
+			return false
+		}
+	}
+
+	//the CRD is detected before the manager starts, so the watches start along with the manager
+	dc.Install(deploymentGVK)
+	assert.Nil(t, d.ScanNow(), "Expect no errors scanning")
+	assert.Zero(t, caches.len(), "Expect no watch before the manager starts")
+	cancel := startManager(t, mgr)
+	assert.Eventually(t, func() bool {
+		return caches.len() == 1
+	}, 5*time.Second, 10*time.Millisecond, "Expect a cache to be created once the manager starts")
+	assert.Eventually(t, func() bool {
+		return reconciles(caches.get(0))
+	}, 5*time.Second, 10*time.Millisecond, "Expect changes to be reconciled once the CRD is detected")
+
+	dc.Uninstall(deploymentGVK)
+	assert.Nil(t, d.ScanNow(), "Expect no errors scanning")
+	assert.Eventually(t, func() bool {
+		for len(requests) > 0 {
+			<-requests
+		}
+		return !reconciles(caches.get(0))
+	}, 5*time.Second, 10*time.Millisecond, "Expect no reconciliation once the CRD is removed")
+
+	dc.Install(deploymentGVK)
+	assert.Nil(t, d.ScanNow(), "Expect no errors scanning")
+	assert.Equal(t, 2, caches.len(), "Expect a new cache to be created once the CRD re-appears")
+	assert.Eventually(t, func() bool {
+		return reconciles(caches.get(1))
+	}, 5*time.Second, 10*time.Millisecond, "Expect changes to be reconciled once the CRD re-appears")
+
+	cancel()
+	for len(requests) > 0 {
+		<-requests
+	}
+	assert.False(t, reconciles(caches.get(1)), "Expect no reconciliation once the manager stops")
+}
+
+func TestDynamicControllerWatchesOnceWithController(t *testing.T) {
+	mgr := newTestManager(t)
+	dc := test.NewFakeDiscoveryBuilder().Build()
+	d, err := NewAutoDetect(dc)
+	assert.Nil(t, err, "Expect no errors creating detector")
+	caches := &fakeCaches{}
+	c := &recordingController{queue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())}
+	defer c.queue.ShutDown()
+	err = NewDynamicController(mgr, d).
+		For(&appsv1.Deployment{}).
+		WithNewCache(caches.newCache).
+		CompleteWithController(c, &handler.EnqueueRequestForObject{})
+	assert.Nil(t, err, "Expect no errors registering dynamic controller")
+	startManager(t, mgr)
+
+	enqueues := func(informer *lockedInformer, name string) bool {
+		informer.Add(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "namespace"}})
+		for c.queue.Len() > 0 {
+			item, _ := c.queue.Get()
+			c.queue.Done(item)
+			if item.(reconcile.Request).Name == name {
+				return true
+			}
+		}
+		return false
+	}
+	for index, name := range []string{"first", "second"} {
+		dc.Install(deploymentGVK)
+		assert.Nil(t, d.ScanNow(), "Expect no errors scanning")
+		assert.Eventually(t, func() bool {
+			return caches.len() == index+1
+		}, 5*time.Second, 10*time.Millisecond, "Expect a cache to be created once the CRD is detected")
+		informer := caches.get(index)
+		assert.Eventually(t, func() bool {
+			return enqueues(informer, name)
+		}, 5*time.Second, 10*time.Millisecond, "Expect events of the cache of each appearance of the CRD")
+		dc.Uninstall(deploymentGVK)
+		assert.Nil(t, d.ScanNow(), "Expect no errors scanning")
+	}
+	assert.Equal(t, 2, caches.len(), "Expect a cache for each appearance of the CRD")
+	assert.Equal(t, 1, c.watches, "Expect the controller to watch the For type once")
+}
+
+func TestDynamicControllerRemovalDoesNotWaitForReconciliation(t *testing.T) {
+	mgr := newTestManager(t)
+	dc := test.NewFakeDiscoveryBuilder().Build()
+	d, err := NewAutoDetect(dc)
+	assert.Nil(t, err, "Expect no errors creating detector")
+	caches := &fakeCaches{}
+	reconciling := make(chan struct{}, 1)
+	release := make(chan struct{})
+	err = NewDynamicController(mgr, d).
+		For(&appsv1.Deployment{}).
+		WithNewCache(caches.newCache).
+		Complete(reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+			select {
+			case reconciling <- struct{}{}:
+			default:
+			}
+			<-release
+			return reconcile.Result{}, nil
+		}))
+	assert.Nil(t, err, "Expect no errors registering dynamic controller")
+	cancel := startManager(t, mgr)
+
+	dc.Install(deploymentGVK)
+	assert.Nil(t, d.ScanNow(), "Expect no errors scanning")
+	assert.Eventually(t, func() bool {
+		return caches.len() == 1
+	}, 5*time.Second, 10*time.Millisecond, "Expect a cache to be created once the CRD is detected")
+	assert.Eventually(t, func() bool {
+		caches.get(0).Add(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "deployment", Namespace: "namespace"}})
+		select {
+		case <-reconciling:
+			return true
+		case <-time.After(10 * time.Millisecond):
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond, "Expect changes to be reconciled once the CRD is detected")
+
+	dc.Uninstall(deploymentGVK)
+	scanned := make(chan error, 1)
+	go func() {
+		scanned <- d.ScanNow()
+	}()
+	select {
+	case err := <-scanned:
+		assert.Nil(t, err, "Expect no errors scanning")
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Detection held up by a running reconciliation")
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		cancel()
+	}()
+	select {
+	case <-stopped:
+		t.Fatalf("Manager stopped before the running reconciliation")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+	<-stopped
+}
+
+func TestDynamicControllerRegistersOnce(t *testing.T) {
+	mgr := newTestManager(t)
+	d, err := NewAutoDetect(test.NewFakeDiscoveryBuilder().Build())
+	assert.Nil(t, err, "Expect no errors creating detector")
+	builder := NewDynamicController(mgr, d).For(&appsv1.Deployment{})
+	assert.Nil(t, builder.Complete(reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+		return reconcile.Result{}, nil
+	})), "Expect no errors registering dynamic controller")
+	c := &recordingController{queue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())}
+	defer c.queue.ShutDown()
+	assert.NotNil(t, builder.CompleteWithController(c, &handler.EnqueueRequestForObject{}), "Expect an error registering the dynamic controller again")
+	assert.Zero(t, c.watches, "Expect no watch added by the failed registration")
+}
+
+func newTestManager(t *testing.T) manager.Manager {
+	scheme := runtime.NewScheme()
+	assert.Nil(t, clientgoscheme.AddToScheme(scheme), "Expect no errors building scheme")
+	mgr, err := manager.New(&rest.Config{Host: "http://127.0.0.1:1"}, manager.Options{
+		Scheme:             scheme,
+		MetricsBindAddress: "0",
+		MapperProvider: func(*rest.Config) (meta.RESTMapper, error) {
+			return meta.NewDefaultRESTMapper(nil), nil
+		},
+	})
+	assert.Nil(t, err, "Expect no errors creating manager")
+	return mgr
+}
+
+// startManager starts the manager until the returned function is called, or the test ends
+func startManager(t *testing.T, mgr manager.Manager) context.CancelFunc {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		assert.Nil(t, mgr.Start(ctx), "Expect no errors running manager")
+	}()
+	stop := func() {
+		cancel()
+		<-stopped
+	}
+	t.Cleanup(stop)
+	return stop
+}
+
+// fakeCaches creates fake caches, and keeps them so that tests can send events through their informers
+type fakeCaches struct {
+	caches []*fakeCache
+	mutex  sync.Mutex
+}
+
+func (f *fakeCaches) newCache(_ *rest.Config, options cache.Options) (cache.Cache, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	kindCache := &fakeCache{FakeInformers: &informertest.FakeInformers{Scheme: options.Scheme}, informer: &lockedInformer{FakeInformer: &controllertest.FakeInformer{}}}
+	f.caches = append(f.caches, kindCache)
+	return kindCache, nil
+}
+
+func (f *fakeCaches) len() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return len(f.caches)
+}
+
+func (f *fakeCaches) get(index int) *lockedInformer {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.caches[index].informer
+}
+
+// fakeCache serves a single informer, which events are sent through by tests while the watches start
+type fakeCache struct {
+	*informertest.FakeInformers
+	informer *lockedInformer
+}
+
+func (c *fakeCache) GetInformer(context.Context, client.Object) (cache.Informer, error) {
+	return c.informer, nil
+}
+
+// lockedInformer is a fake informer that is safe to send events through while handlers are added
+type lockedInformer struct {
+	*controllertest.FakeInformer
+	mutex sync.Mutex
+}
+
+func (i *lockedInformer) AddEventHandler(eventHandler toolscache.ResourceEventHandler) (toolscache.ResourceEventHandlerRegistration, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	return i.FakeInformer.AddEventHandler(eventHandler)
+}
+
+func (i *lockedInformer) Add(object metav1.Object) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.FakeInformer.Add(object)
+}
+
+// recordingController counts its watches, and starts their sources right away with its queue, like a started controller
+type recordingController struct {
+	controller.Controller
+	queue   workqueue.RateLimitingInterface
+	watches int
+}
+
+func (c *recordingController) Watch(src source.Source, eventHandler handler.EventHandler, predicates ...predicate.Predicate) error {
+	c.watches++
+	return src.Start(context.Background(), eventHandler, c.queue, predicates...)
+}
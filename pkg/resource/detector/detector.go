@@ -1,31 +1,145 @@
 package detector
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
 	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/utils/clock"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
+var logger = ctrl.Log.WithName("detector")
+
+const defaultInterval = 30 * time.Second
+
+var _ manager.Runnable = &Detector{}
+var _ manager.LeaderElectionRunnable = &Detector{}
+
 // Detector represents a procedure that runs in the background, periodically auto-detecting features
 type Detector struct {
-	dc     discovery.DiscoveryInterface
-	ticker *time.Ticker
-	crds   map[runtime.Object]trigger
+	dc             discovery.DiscoveryInterface
+	interval       time.Duration
+	crds           map[runtime.Object]*crdTriggers
+	versionedKinds map[schema.GroupKind]*versionedKind
+	features       map[string]*feature
+	featureReader  client.Reader
+	watchClient    client.WithWatch
+	state          *StateManager
+	capabilities   *Capabilities
+	errorHandler   ErrorHandler
+	backoff        *flowcontrol.Backoff
+	leaderElection bool
+	cancel         context.CancelFunc
+	stopped        bool
+	clock          clock.WithTicker
+	scanned        chan struct{}
+	scannedOnce    sync.Once
+	scanMutex      sync.Mutex
+	mutex          sync.Mutex
 }
 
 type trigger func(runtime.Object)
 
+// crdTriggers holds the functions to run when a registered CRD appears, and when it is removed, and whether it was last detected.
+// When the CRD watch reports a change, it prevails over discovery until a scan agrees, as discovery lags behind the watch
+type crdTriggers struct {
+	added    trigger
+	removed  trigger
+	detected bool
+	watched  *bool
+}
+
 // New creates a new auto-detect runner
 func NewAutoDetect(dc discovery.DiscoveryInterface) (*Detector, error) {
-	return &Detector{dc: dc, crds: map[runtime.Object]trigger{}}, nil
+	return &Detector{
+		dc:             dc,
+		interval:       defaultInterval,
+		crds:           map[runtime.Object]*crdTriggers{},
+		versionedKinds: map[schema.GroupKind]*versionedKind{},
+		features:       map[string]*feature{},
+		state:          NewStateManager(),
+		backoff:        newDiscoveryBackoff(),
+		clock:          clock.RealClock{},
+		scanned:        make(chan struct{}),
+	}, nil
+}
+
+// WithInterval sets the interval between scans, which defaults to 30 seconds
+func (d *Detector) WithInterval(interval time.Duration) *Detector {
+	d.interval = interval
+	return d
+}
+
+// WithClock sets the clock that schedules scans and backoffs, for example a fake clock in tests
+func (d *Detector) WithClock(clock clock.WithTicker) *Detector {
+	d.clock = clock
+	d.backoff.Clock = clock
+	return d
+}
+
+// WithLeaderElection sets whether the detector only runs in the elected leader, when added to a manager
+// by default, it runs in every replica, so that each replica reacts to CRDs that show up
+func (d *Detector) WithLeaderElection(needLeaderElection bool) *Detector {
+	d.leaderElection = needLeaderElection
+	return d
+}
+
+// WithStateManager sets the store where the detector records whether each CRD exists, under the key returned by CRDStateKey
+// by default, each detector has its own state manager
+func (d *Detector) WithStateManager(state *StateManager) *Detector {
+	d.state = state
+	return d
+}
+
+// State returns the store where the detector records whether each CRD exists, for example to subscribe to changes
+func (d *Detector) State() *StateManager {
+	return d.state
+}
+
+// IsCRDDetected returns true if the detector has found that the CRD with the given group, version and kind currently exists
+func (d *Detector) IsCRDDetected(gvk schema.GroupVersionKind) bool {
+	return d.state.GetBoolState(CRDStateKey(gvk))
+}
+
+// CRDStateKey returns the key under which the detection state of a CRD is recorded, as a bool
+func CRDStateKey(gvk schema.GroupVersionKind) string {
+	return "crd:" + gvk.String()
 }
 
 // AddCRDTrigger to run the trigger function,
-// the first time that the background scanner discovers that the CRD type specified exists
+// the first time that the background scanner discovers that the CRD type specified exists, and again each time it re-appears after removal
 func (d *Detector) AddCRDTrigger(crd runtime.Object, trigger trigger) {
-	d.crds[crd] = trigger
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.getTriggers(crd).added = trigger
+}
+
+// AddCRDRemovedTrigger to run the trigger function,
+// each time that the background scanner discovers that the previously detected CRD type specified no longer exists
+func (d *Detector) AddCRDRemovedTrigger(crd runtime.Object, trigger trigger) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.getTriggers(crd).removed = trigger
+}
+
+// getTriggers returns the triggers registered for the CRD, and must be called while holding the mutex
+func (d *Detector) getTriggers(crd runtime.Object) *crdTriggers {
+	triggers, ok := d.crds[crd]
+	if !ok {
+		triggers = &crdTriggers{}
+		d.crds[crd] = triggers
+	}
+	return triggers
 }
 
 // AddCRDsTrigger to run the trigger function,
@@ -44,45 +158,170 @@ func (d *Detector) AddCRDsWithTriggers(crdsTriggers map[runtime.Object]trigger)
 	}
 }
 
-// Start initializes the auto-detection process that runs in the background
-func (d *Detector) Start(interval time.Duration) {
-	go func() {
-		d.autoDetectCapabilities()
-		d.ticker = time.NewTicker(interval)
-		for range d.ticker.C {
-			d.autoDetectCapabilities()
+// Start runs the auto-detection process until the context is cancelled or Stop is called, and implements manager.Runnable,
+// so that the detector can be added to a manager, or otherwise run in the background with: go d.Start(ctx)
+// if a CRD watch is configured, it is started as well, while scanning at the configured interval remains as a fallback
+// a detector can only be started once, and returns immediately if it was stopped before it started
+func (d *Detector) Start(ctx context.Context) error {
+	d.mutex.Lock()
+	if d.stopped {
+		d.mutex.Unlock()
+		return nil
+	}
+	if d.cancel != nil {
+		d.mutex.Unlock()
+		return fmt.Errorf("detector is already started")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	d.cancel = cancel
+	d.mutex.Unlock()
+	defer cancel()
+
+	if d.watchClient != nil {
+		d.startCRDWatch(ctx)
+	}
+	ticker := d.clock.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		//errors are reported as they occur, and do not prevent other CRDs from being detected
+		_ = d.ScanNow()
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C():
 		}
-	}()
+	}
+}
+
+// ScanNow synchronously scans for the registered CRDs and runs the triggers of those that appeared or were removed, whether or not
+// the detector is started, and returns the errors of the scan. Tests can use it to observe the effect of CRDs being installed or removed
+func (d *Detector) ScanNow() error {
+	d.scanMutex.Lock()
+	defer d.scanMutex.Unlock()
+	err := d.autoDetectCapabilities()
+	d.scannedOnce.Do(func() {
+		close(d.scanned)
+	})
+	return err
 }
 
-// Stop causes the background process to stop auto detecting capabilities
+// Stop causes the background process to stop auto detecting capabilities, and may be called before the detector is started
 func (d *Detector) Stop() {
-	d.ticker.Stop()
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.stopped = true
+	if d.cancel != nil {
+		d.cancel()
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, based on the choice made with WithLeaderElection
+func (d *Detector) NeedLeaderElection() bool {
+	return d.leaderElection
 }
 
-func (d *Detector) autoDetectCapabilities() {
-	for crd, trigger := range d.crds {
-		crdGVK := crd.GetObjectKind().GroupVersionKind()
-		apiLists, err := d.dc.ServerResourcesForGroupVersion(crdGVK.GroupVersion().String())
-		if err != nil {
-			return
+// ReadyCheck implements healthz.Checker, and reports an error until the first scan has completed
+// for example: mgr.AddReadyzCheck("detector", d.ReadyCheck)
+func (d *Detector) ReadyCheck(_ *http.Request) error {
+	select {
+	case <-d.scanned:
+		return nil
+	default:
+		return fmt.Errorf("detector has not completed its first scan")
+	}
+}
+
+// autoDetectCapabilities scans for the registered CRDs, and returns the errors of the scan, which are reported as they occur
+// a CRD whose group version cannot be discovered, or is backing off after failures, keeps its previous state until a later scan
+func (d *Detector) autoDetectCapabilities() error {
+	start := d.clock.Now()
+	defer func() {
+		scanDuration.Observe(d.clock.Since(start).Seconds())
+	}()
+	discovered := d.newDiscoveryScan()
+	d.detectCapabilities(discovered)
+	for _, crd := range d.getCRDs() {
+		exists, known := discovered.servesKind(crd.GetObjectKind().GroupVersionKind())
+		if !known || !d.agreesWithWatch(crd, exists) {
+			continue
 		}
-		resourceExists := d.resourceExists(apiLists, crdGVK.Kind)
-		if resourceExists {
-			stateManager := GetStateManager()
-			if stateManager.GetState(crdGVK.Kind) != true {
-				stateManager.SetState(crdGVK.Kind, true)
-				trigger(crd)
-			}
+		if exists {
+			d.setDetected(crd)
+		} else {
+			d.setRemoved(crd)
 		}
 	}
+	d.detectVersions(discovered)
+	d.detectFeatures(discovered)
+	return utilerrors.NewAggregate(discovered.errs)
 }
 
-func (d *Detector) resourceExists(apiList *metav1.APIResourceList, kind string) bool {
-	for _, r := range apiList.APIResources {
-		if r.Kind == kind {
-			return true
-		}
+// setDetected records that the CRD exists, and runs the added trigger if it was not detected before, or has since been removed
+func (d *Detector) setDetected(crd runtime.Object) {
+	crdGVK := crd.GetObjectKind().GroupVersionKind()
+	detectedCRDs.WithLabelValues(crdGVK.Group, crdGVK.Version, crdGVK.Kind).Set(1)
+	d.state.SetState(CRDStateKey(crdGVK), true)
+	if triggers, changed := d.transition(crd, true); changed && triggers.added != nil {
+		triggers.added(crd)
+	}
+}
+
+// setRemoved records that the CRD does not exist, and runs the removed trigger if it was previously detected
+func (d *Detector) setRemoved(crd runtime.Object) {
+	crdGVK := crd.GetObjectKind().GroupVersionKind()
+	detectedCRDs.WithLabelValues(crdGVK.Group, crdGVK.Version, crdGVK.Kind).Set(0)
+	d.state.SetState(CRDStateKey(crdGVK), false)
+	if triggers, changed := d.transition(crd, false); changed && triggers.removed != nil {
+		triggers.removed(crd)
+	}
+}
+
+// transition records whether the CRD exists for its registration, and returns true if this is a change from the previously recorded state,
+// along with the triggers to run. Transitions are tracked for each registration, so that registrations of the same kind do not interfere,
+// and a CRD that was never detected is considered absent, so that removed triggers only run after the CRD was detected
+func (d *Detector) transition(crd runtime.Object, exists bool) (crdTriggers, bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	triggers, ok := d.crds[crd]
+	if !ok || triggers.detected == exists {
+		return crdTriggers{}, false
+	}
+	triggers.detected = exists
+	return *triggers, true
+}
+
+// setWatched records the existence of the CRD reported by the CRD watch, which prevails over discovery until discovery agrees
+func (d *Detector) setWatched(crd runtime.Object, exists bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if triggers, ok := d.crds[crd]; ok {
+		triggers.watched = &exists
+	}
+}
+
+// agreesWithWatch returns false if the CRD watch reported that the CRD exists while discovery does not serve it yet, or the reverse,
+// and otherwise returns true, and lets discovery prevail again
+func (d *Detector) agreesWithWatch(crd runtime.Object, exists bool) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	triggers, ok := d.crds[crd]
+	if !ok || triggers.watched == nil {
+		return true
+	}
+	if *triggers.watched != exists {
+		return false
+	}
+	triggers.watched = nil
+	return true
+}
+
+// getCRDs returns the registered CRDs, which can be iterated while other CRDs are being registered
+func (d *Detector) getCRDs() []runtime.Object {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	crds := make([]runtime.Object, 0, len(d.crds))
+	for crd := range d.crds {
+		crds = append(crds, crd)
 	}
-	return false
+	return crds
 }
@@ -1,29 +1,28 @@
 package detector
 
 import (
+	"context"
+	"github.com/RHsyseng/operator-utils/pkg/test"
+	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	discoveryFake "k8s.io/client-go/discovery/fake"
-	k8sTesting "k8s.io/client-go/testing"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	testingclock "k8s.io/utils/clock/testing"
+	"net/http"
 	"testing"
 	"time"
 )
 
 func TestDetectorDetects(t *testing.T) {
 	crdDiscovered := false
-	dc := &discoveryFake.FakeDiscovery{
-		Fake:               &k8sTesting.Fake{},
-		FakedServerVersion: nil,
-	}
+	dc := test.NewFakeDiscoveryBuilder().Build()
 
 	d, err := NewAutoDetect(dc)
 	if err != nil {
 		t.Fatalf("expected no errors, got: %s", err.Error())
 	}
 
-	// run very frequently, for faster tests
-	d.Start(10 * time.Nanosecond)
 	d.AddCRDTrigger(&appsv1.Deployment{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "deployment",
@@ -34,23 +33,180 @@ func TestDetectorDetects(t *testing.T) {
 
 	})
 
-	//wait a few intervals
-	time.Sleep(10 * time.Millisecond)
+	//scan synchronously, rather than waiting for intervals
+	if err := d.ScanNow(); err != nil {
+		t.Fatalf("expected no errors, got: %s", err.Error())
+	}
 
 	if crdDiscovered {
 		t.Fatalf("CRD Discovered too early")
 	}
 
-	dc.Resources = []*metav1.APIResourceList{
-		{
-			TypeMeta:     metav1.TypeMeta{},
-			GroupVersion: "apps/v1",
-			APIResources: []metav1.APIResource{{Kind: "deployment"}},
-		},
-	}
+	dc.Install(appsv1.SchemeGroupVersion.WithKind("deployment"))
 
-	time.Sleep(10 * time.Millisecond)
+	if err := d.ScanNow(); err != nil {
+		t.Fatalf("expected no errors, got: %s", err.Error())
+	}
 	if !crdDiscovered {
 		t.Fatalf("CRD not discovered correctly")
 	}
 }
+
+func TestDetectorScansOnClockTicks(t *testing.T) {
+	dc := test.NewFakeDiscoveryBuilder().Build()
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	d, err := NewAutoDetect(dc)
+	assert.Nil(t, err, "Expect no errors creating detector")
+	gvk := schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}
+	crd := &metav1.PartialObjectMetadata{}
+	crd.SetGroupVersionKind(gvk)
+	detected := make(chan bool, 2)
+	d.AddCRDTrigger(crd, func(runtime.Object) {
+		detected <- true
+	})
+	d.AddCRDRemovedTrigger(crd, func(runtime.Object) {
+		detected <- false
+	})
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	go d.WithClock(fakeClock).WithInterval(time.Minute).Start(ctx)
+	assert.Eventually(t, func() bool {
+		return d.ReadyCheck(&http.Request{}) == nil && fakeClock.HasWaiters()
+	}, 5*time.Second, time.Millisecond, "Expect the first scan to complete, and the detector to wait for the next tick")
+
+	dc.Install(gvk)
+	fakeClock.Step(time.Minute)
+	assert.True(t, <-detected, "Expect the installed CRD to be detected on the next tick")
+	dc.Uninstall(gvk)
+	fakeClock.Step(time.Minute)
+	assert.False(t, <-detected, "Expect the removed CRD to be detected on the next tick")
+}
+
+func TestDetectorDetectsRemovalAndReappearance(t *testing.T) {
+	dc := test.NewFakeDiscoveryBuilder().Build()
+	d, err := NewAutoDetect(dc)
+	assert.Nil(t, err, "Expect no errors creating detector")
+
+	var events []string
+	serviceMonitorGVK := schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}
+	podMonitorGVK := serviceMonitorGVK.GroupVersion().WithKind("PodMonitor")
+	serviceMonitor := &metav1.PartialObjectMetadata{}
+	serviceMonitor.SetGroupVersionKind(serviceMonitorGVK)
+	d.AddCRDTrigger(serviceMonitor, func(crd runtime.Object) {
+		events = append(events, "added")
+	})
+	d.AddCRDRemovedTrigger(serviceMonitor, func(crd runtime.Object) {
+		events = append(events, "removed")
+	})
+
+	//scan synchronously, so that each transition is observed
+	assert.Nil(t, d.ScanNow(), "Expect no errors scanning")
+	assert.Empty(t, events, "Expect no triggers while the CRD was never detected")
+	dc.Install(serviceMonitorGVK)
+	assert.Nil(t, d.ScanNow(), "Expect no errors scanning")
+	assert.Nil(t, d.ScanNow(), "Expect no errors scanning")
+	assert.Equal(t, []string{"added"}, events, "Expect the added trigger to run once")
+
+	dc.Uninstall(serviceMonitorGVK)
+	assert.Nil(t, d.ScanNow(), "Expect no errors scanning")
+	assert.Nil(t, d.ScanNow(), "Expect no errors scanning")
+	assert.Equal(t, []string{"added", "removed"}, events, "Expect the removed trigger to run once, after the group version is gone")
+
+	dc.Install(serviceMonitorGVK)
+	assert.Nil(t, d.ScanNow(), "Expect no errors scanning")
+	assert.Equal(t, []string{"added", "removed", "added"}, events, "Expect the added trigger to run again on re-appearance")
+
+	dc.Install(podMonitorGVK)
+	dc.Uninstall(serviceMonitorGVK)
+	assert.Nil(t, d.ScanNow(), "Expect no errors scanning")
+	assert.Equal(t, []string{"added", "removed", "added", "removed"}, events, "Expect the removed trigger to run when only the kind is gone")
+}
+
+func TestDetectorStateIsKeyedByGVK(t *testing.T) {
+	openShiftRoute := schema.GroupVersionKind{Group: "route.openshift.io", Version: "v1", Kind: "Route"}
+	otherRoute := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Route"}
+	dc := test.NewFakeDiscoveryBuilder().WithKinds(openShiftRoute).Build()
+
+	var detected []schema.GroupVersionKind
+	d, err := NewAutoDetect(dc)
+	assert.Nil(t, err, "Expect no errors creating detector")
+	for _, gvk := range []schema.GroupVersionKind{openShiftRoute, otherRoute} {
+		crd := &metav1.PartialObjectMetadata{}
+		crd.SetGroupVersionKind(gvk)
+		d.AddCRDTrigger(crd, func(crd runtime.Object) {
+			detected = append(detected, crd.GetObjectKind().GroupVersionKind())
+		})
+	}
+	assert.Nil(t, d.ScanNow(), "Expect no errors scanning")
+	assert.Equal(t, []schema.GroupVersionKind{openShiftRoute}, detected, "Expect only the served Route to be detected")
+	assert.True(t, d.IsCRDDetected(openShiftRoute), "Expect the served Route to be detected")
+	assert.False(t, d.IsCRDDetected(otherRoute), "Expect a Route of another group not to be detected")
+
+	other, err := NewAutoDetect(dc)
+	assert.Nil(t, err, "Expect no errors creating detector")
+	assert.False(t, other.IsCRDDetected(openShiftRoute), "Expect detectors not to share state by default")
+
+	shared := NewStateManager()
+	var notified []interface{}
+	shared.Subscribe(CRDStateKey(openShiftRoute), func(key string, oldValue interface{}, newValue interface{}) {
+		notified = append(notified, newValue)
+	})
+	other.WithStateManager(shared)
+	crd := &metav1.PartialObjectMetadata{}
+	crd.SetGroupVersionKind(openShiftRoute)
+	other.AddCRDTrigger(crd, func(crd runtime.Object) {})
+	assert.Nil(t, other.ScanNow(), "Expect no errors scanning")
+	assert.True(t, shared.GetBoolState(CRDStateKey(openShiftRoute)), "Expect detection to be recorded in the injected state manager")
+	assert.Equal(t, []interface{}{true}, notified, "Expect subscribers to be notified of the detection")
+}
+
+func TestDetectorLifecycle(t *testing.T) {
+	dc := test.NewFakeDiscoveryBuilder().Build()
+	d, err := NewAutoDetect(dc)
+	assert.Nil(t, err, "Expect no errors creating detector")
+	assert.False(t, d.NeedLeaderElection(), "Expect detectors to run in every replica by default")
+	assert.True(t, d.WithLeaderElection(true).NeedLeaderElection(), "Expect the leader election choice to be configurable")
+
+	stopped, err := NewAutoDetect(dc)
+	assert.Nil(t, err, "Expect no errors creating detector")
+	stopped.Stop()
+	assert.Nil(t, stopped.Start(context.TODO()), "Expect a detector stopped before it started to return immediately")
+
+	assert.NotNil(t, d.ReadyCheck(&http.Request{}), "Expect the detector not to be ready before its first scan")
+	ctx, cancel := context.WithCancel(context.TODO())
+	done := make(chan error)
+	go func() {
+		done <- d.WithInterval(time.Hour).Start(ctx)
+	}()
+	assert.Eventually(t, func() bool {
+		return d.ReadyCheck(&http.Request{}) == nil
+	}, 5*time.Second, 10*time.Millisecond, "Expect the detector to be ready after its first scan")
+	assert.NotNil(t, d.Start(ctx), "Expect a detector not to start twice")
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.Nil(t, err, "Expect no errors when the context is cancelled")
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Detector did not stop when the context was cancelled")
+	}
+	d.Stop()
+}
+
+func TestDetectorRunsTriggersOfEachRegistration(t *testing.T) {
+	dc := test.NewFakeDiscoveryBuilder().WithKinds(schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}).Build()
+	d, err := NewAutoDetect(dc)
+	assert.Nil(t, err, "Expect no errors creating detector")
+	detected := 0
+	for i := 0; i < 2; i++ {
+		d.AddCRDTrigger(&metav1.PartialObjectMetadata{
+			TypeMeta: metav1.TypeMeta{Kind: "ServiceMonitor", APIVersion: "monitoring.coreos.com/v1"},
+		}, func(crd runtime.Object) {
+			detected++
+		})
+	}
+	assert.Nil(t, d.ScanNow(), "Expect no errors scanning")
+	assert.Nil(t, d.ScanNow(), "Expect no errors scanning")
+	assert.Equal(t, 2, detected, "Expect the trigger of each registration of the same kind to run once")
+}
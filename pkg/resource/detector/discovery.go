@@ -0,0 +1,158 @@
+package detector
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+const (
+	defaultInitialBackoff = 5 * time.Second
+	defaultMaxBackoff     = 5 * time.Minute
+)
+
+// ErrorHandler is called with the group version that could not be discovered, which is empty if the served groups could not be listed
+type ErrorHandler func(groupVersion schema.GroupVersion, err error)
+
+// WithErrorHandler sets a function to call when discovery fails, in addition to the failure being logged and counted
+func (d *Detector) WithErrorHandler(errorHandler ErrorHandler) *Detector {
+	d.errorHandler = errorHandler
+	return d
+}
+
+// WithBackoff sets the initial and maximum duration for which a group version is not requested again after discovery fails,
+// which doubles on each consecutive failure, and defaults to 5 seconds up to 5 minutes
+func (d *Detector) WithBackoff(initial time.Duration, max time.Duration) *Detector {
+	d.backoff = flowcontrol.NewBackOff(initial, max)
+	d.backoff.Clock = d.clock
+	return d
+}
+
+// reportError logs and counts the discovery failure, and calls the error handler if set
+func (d *Detector) reportError(groupVersion schema.GroupVersion, err error) {
+	logger.Error(err, "Failed to discover API resources", "groupVersion", groupVersion.String())
+	discoveryErrors.WithLabelValues(groupVersion.Group, groupVersion.Version).Inc()
+	if d.errorHandler != nil {
+		d.errorHandler(groupVersion, err)
+	}
+}
+
+// discoveryScan caches the discovery responses within a scan, so that each group version is only requested once,
+// and skips the group versions that are backing off after failures
+type discoveryScan struct {
+	detector *Detector
+	apiLists map[string]*metav1.APIResourceList
+	groups   *metav1.APIGroupList
+	failed   map[string]bool
+	errs     []error
+}
+
+func (d *Detector) newDiscoveryScan() *discoveryScan {
+	return &discoveryScan{detector: d, apiLists: map[string]*metav1.APIResourceList{}, failed: map[string]bool{}}
+}
+
+// servesKind returns true if the kind is served at the group version, and false as the second value if this is unknown
+// because the group version could not be discovered, or is backing off after failures
+func (s *discoveryScan) servesKind(gvk schema.GroupVersionKind) (bool, bool) {
+	apiList, ok := s.getAPIList(gvk.GroupVersion())
+	if !ok {
+		return false, false
+	}
+	for _, r := range apiList.APIResources {
+		if r.Kind == gvk.Kind {
+			return true, true
+		}
+	}
+	return false, true
+}
+
+func (s *discoveryScan) getAPIList(groupVersion schema.GroupVersion) (*metav1.APIResourceList, bool) {
+	key := groupVersion.String()
+	if apiList, found := s.apiLists[key]; found {
+		return apiList, true
+	}
+	if !s.request(key) {
+		return nil, false
+	}
+	apiList, err := s.detector.dc.ServerResourcesForGroupVersion(key)
+	if errors.IsNotFound(err) {
+		//the group version is no longer served, which is the case when its last CRD is removed
+		apiList = &metav1.APIResourceList{GroupVersion: key}
+	} else if err != nil {
+		s.fail(key, groupVersion, err)
+		return nil, false
+	}
+	s.succeed(key)
+	s.apiLists[key] = apiList
+	return apiList, true
+}
+
+// serverGroups returns the groups served by the cluster along with their preferred version, and false if they could not be listed
+func (s *discoveryScan) serverGroups() (*metav1.APIGroupList, bool) {
+	if s.groups != nil {
+		return s.groups, true
+	}
+	//the served groups are backed off under an empty key, which no group version can have
+	if !s.request("") {
+		return nil, false
+	}
+	groups, err := s.detector.dc.ServerGroups()
+	if err != nil {
+		s.fail("", schema.GroupVersion{}, err)
+		return nil, false
+	}
+	s.succeed("")
+	s.groups = groups
+	return groups, true
+}
+
+// request returns false if the key already failed within this scan, or is backing off after failures in previous scans
+func (s *discoveryScan) request(key string) bool {
+	backoff := s.detector.backoff
+	return !s.failed[key] && !backoff.IsInBackOffSinceUpdate(key, backoff.Clock.Now())
+}
+
+func (s *discoveryScan) fail(key string, groupVersion schema.GroupVersion, err error) {
+	backoff := s.detector.backoff
+	backoff.Next(key, backoff.Clock.Now())
+	s.failed[key] = true
+	if groupVersion.Empty() {
+		err = fmt.Errorf("failed to list served groups: %w", err)
+	} else {
+		err = fmt.Errorf("failed to discover %s: %w", key, err)
+	}
+	s.errs = append(s.errs, err)
+	s.detector.reportError(groupVersion, err)
+}
+
+// failFeature backs off the feature, and logs and counts the failure to evaluate it
+func (s *discoveryScan) failFeature(key string, name string, err error) {
+	backoff := s.detector.backoff
+	backoff.Next(key, backoff.Clock.Now())
+	err = fmt.Errorf("failed to evaluate feature %s: %w", name, err)
+	s.errs = append(s.errs, err)
+	logger.Error(err, "Failed to evaluate cluster feature", "feature", name)
+	featureErrors.WithLabelValues(name).Inc()
+}
+
+// failPlatform backs off the detection of the platform, and logs the failure
+func (s *discoveryScan) failPlatform(err error) {
+	backoff := s.detector.backoff
+	backoff.Next(platformKey, backoff.Clock.Now())
+	err = fmt.Errorf("failed to detect the platform: %w", err)
+	s.errs = append(s.errs, err)
+	logger.Error(err, "Failed to detect the platform")
+}
+
+func (s *discoveryScan) succeed(key string) {
+	s.detector.backoff.Reset(key)
+}
+
+// newDiscoveryBackoff creates the default backoff for group versions that cannot be discovered
+func newDiscoveryBackoff() *flowcontrol.Backoff {
+	return flowcontrol.NewBackOff(defaultInitialBackoff, defaultMaxBackoff)
+}
@@ -0,0 +1,85 @@
+package detector
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/RHsyseng/operator-utils/pkg/test"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+// failingDiscovery fails to discover a group version, and counts the requests for it
+type failingDiscovery struct {
+	*test.FakeDiscovery
+	failing  string
+	requests int
+}
+
+func (f *failingDiscovery) ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error) {
+	if groupVersion == f.failing {
+		f.requests++
+		return nil, fmt.Errorf("the server is currently unable to handle the request")
+	}
+	return f.FakeDiscovery.ServerResourcesForGroupVersion(groupVersion)
+}
+
+func TestDetectorReportsErrorsAndBacksOff(t *testing.T) {
+	dc := &failingDiscovery{
+		FakeDiscovery: test.NewFakeDiscoveryBuilder().
+			WithKinds(schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}).
+			Build(),
+		failing: "metrics.k8s.io/v1beta1",
+	}
+	d, err := NewAutoDetect(dc)
+	assert.Nil(t, err, "Expect no errors creating detector")
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	d.WithClock(fakeClock).WithBackoff(time.Second, 4*time.Second)
+	var reported []schema.GroupVersion
+	d.WithErrorHandler(func(groupVersion schema.GroupVersion, err error) {
+		reported = append(reported, groupVersion)
+	})
+	detected := map[string]bool{}
+	for _, crd := range []*metav1.PartialObjectMetadata{
+		{TypeMeta: metav1.TypeMeta{Kind: "PodMetrics", APIVersion: "metrics.k8s.io/v1beta1"}},
+		{TypeMeta: metav1.TypeMeta{Kind: "ServiceMonitor", APIVersion: "monitoring.coreos.com/v1"}},
+	} {
+		d.AddCRDTrigger(crd, func(crd runtime.Object) {
+			detected[crd.GetObjectKind().GroupVersionKind().Kind] = true
+		})
+	}
+	failures := testutil.ToFloat64(discoveryErrors.WithLabelValues("metrics.k8s.io", "v1beta1"))
+
+	assert.NotNil(t, d.ScanNow(), "Expect the scan to return the discovery error")
+	assert.True(t, detected["ServiceMonitor"], "Expect a failing group not to prevent the detection of other CRDs")
+	assert.False(t, detected["PodMetrics"], "Expect a CRD of a failing group not to be detected")
+	assert.Equal(t, []schema.GroupVersion{{Group: "metrics.k8s.io", Version: "v1beta1"}}, reported, "Expect the error to be reported")
+	assert.Equal(t, failures+1, testutil.ToFloat64(discoveryErrors.WithLabelValues("metrics.k8s.io", "v1beta1")), "Expect the error to be counted")
+
+	//the backoff doubles from 1 second on each consecutive failure
+	for _, step := range []struct {
+		elapsed  time.Duration
+		requests int
+	}{
+		{0, 1},
+		{time.Second, 2},
+		{time.Second, 2},
+		{time.Second, 3},
+		{3 * time.Second, 3},
+		{time.Second, 4},
+	} {
+		fakeClock.Step(step.elapsed)
+		d.ScanNow()
+		assert.Equal(t, step.requests, dc.requests, "Expect the failing group version to be requested after its backoff")
+	}
+
+	dc.failing = ""
+	fakeClock.Step(4 * time.Second)
+	assert.Nil(t, d.ScanNow(), "Expect no errors once the group version recovers")
+	assert.Len(t, reported, 4, "Expect each failure to be reported")
+}
@@ -0,0 +1,244 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/ghodss/yaml"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// FeatureDefaultStorageClass is the name of the default StorageClass, or an empty string if there is none
+	FeatureDefaultStorageClass = "defaultStorageClass"
+	// FeatureIngressClasses is the sorted names of the IngressClasses
+	FeatureIngressClasses = "ingressClasses"
+	// FeatureIngressDomain is the default domain of the OpenShift ingress controller, or an empty string on other platforms
+	FeatureIngressDomain = "ingressDomain"
+	// FeatureMultiArch is whether the nodes of the cluster have more than one architecture
+	FeatureMultiArch = "multiArch"
+	// FeatureFIPS is whether the OpenShift cluster was installed with FIPS mode enabled, or nil if the reader is not allowed to know
+	FeatureFIPS = "fips"
+
+	isDefaultStorageClassAnnotation     = "storageclass.kubernetes.io/is-default-class"
+	betaIsDefaultStorageClassAnnotation = "storageclass.beta.kubernetes.io/is-default-class"
+)
+
+// FeatureFunc evaluates a feature of the cluster through the reader, and returns its current value
+type FeatureFunc func(ctx context.Context, reader client.Reader) (interface{}, error)
+
+// FeatureTrigger is called with the name and new value of a feature, each time that its value changes
+type FeatureTrigger func(name string, value interface{})
+
+// feature holds the function that evaluates a registered feature, and the triggers to run when its value changes
+type feature struct {
+	evaluate FeatureFunc
+	triggers []FeatureTrigger
+}
+
+// WithFeatureReader sets the reader through which features are evaluated, which should not be a cached client,
+// such as the API reader of the manager, to avoid caching all nodes and storage classes for instance
+func (d *Detector) WithFeatureReader(reader client.Reader) *Detector {
+	d.featureReader = reader
+	return d
+}
+
+// AddFeature registers a feature of the cluster, which is evaluated on each scan and recorded under the key returned by FeatureStateKey
+func (d *Detector) AddFeature(name string, evaluate FeatureFunc) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	registration, ok := d.features[name]
+	if !ok {
+		registration = &feature{}
+		d.features[name] = registration
+	}
+	registration.evaluate = evaluate
+}
+
+// AddFeatureTrigger to run the trigger function, the first time that the feature is evaluated, and each time that its value changes
+func (d *Detector) AddFeatureTrigger(name string, trigger FeatureTrigger) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	registration, ok := d.features[name]
+	if !ok {
+		registration = &feature{}
+		d.features[name] = registration
+	}
+	registration.triggers = append(registration.triggers, trigger)
+}
+
+// AddClusterFeatures registers the default StorageClass, IngressClasses, ingress domain, multi-arch and FIPS features,
+// which are read through the feature reader, and require the RBAC rules:
+//   - list storageclasses of the storage.k8s.io group
+//   - list ingressclasses of the networking.k8s.io group
+//   - list nodes of the core group
+//   - get ingresses of the config.openshift.io group, named cluster, on OpenShift
+//   - get configmaps of the core group, named cluster-config-v1, in the kube-system namespace
+//
+// Without the last rule, FIPS mode is unknown, so that GetFeature reports it as not evaluated, while the other features fail and are backed off
+func (d *Detector) AddClusterFeatures() *Detector {
+	d.AddFeature(FeatureDefaultStorageClass, DetectDefaultStorageClass)
+	d.AddFeature(FeatureIngressClasses, DetectIngressClasses)
+	d.AddFeature(FeatureIngressDomain, DetectIngressDomain)
+	d.AddFeature(FeatureMultiArch, DetectMultiArch)
+	d.AddFeature(FeatureFIPS, DetectFIPS)
+	return d
+}
+
+// FeatureStateKey returns the key under which the value of a feature is recorded
+func FeatureStateKey(name string) string {
+	return "feature:" + name
+}
+
+// GetFeature returns the value of the feature as type T, and whether it has been evaluated
+// for example: domain, ok := detector.GetFeature[string](d, detector.FeatureIngressDomain)
+func GetFeature[T any](d *Detector, name string) (T, bool) {
+	return GetTypedState[T](d.state, FeatureStateKey(name))
+}
+
+// detectFeatures evaluates each registered feature, and runs the triggers of those whose value changed
+// a feature that fails to be evaluated keeps its previous value, and is backed off like a group version that cannot be discovered
+func (d *Detector) detectFeatures(discovered *discoveryScan) {
+	d.mutex.Lock()
+	features := make(map[string]feature, len(d.features))
+	for name, registration := range d.features {
+		if registration.evaluate != nil {
+			features[name] = *registration
+		}
+	}
+	d.mutex.Unlock()
+	if len(features) == 0 {
+		return
+	}
+	if d.featureReader == nil {
+		discovered.errs = append(discovered.errs, fmt.Errorf("features cannot be evaluated without a reader"))
+		return
+	}
+	for name, registration := range features {
+		key := FeatureStateKey(name)
+		if !discovered.request(key) {
+			continue
+		}
+		value, err := registration.evaluate(context.TODO(), d.featureReader)
+		if err != nil {
+			discovered.failFeature(key, name, err)
+			continue
+		}
+		discovered.succeed(key)
+		previous := d.state.SwapState(key, value)
+		if !reflect.DeepEqual(previous, value) {
+			for _, trigger := range registration.triggers {
+				trigger(name, value)
+			}
+		}
+	}
+}
+
+// DetectDefaultStorageClass returns the name of the default StorageClass, the most recently created if several are marked as default
+func DetectDefaultStorageClass(ctx context.Context, reader client.Reader) (interface{}, error) {
+	storageClasses := &storagev1.StorageClassList{}
+	if err := reader.List(ctx, storageClasses); err != nil {
+		return nil, err
+	}
+	var defaultClass *storagev1.StorageClass
+	for index := range storageClasses.Items {
+		storageClass := &storageClasses.Items[index]
+		annotations := storageClass.GetAnnotations()
+		if annotations[isDefaultStorageClassAnnotation] != "true" && annotations[betaIsDefaultStorageClassAnnotation] != "true" {
+			continue
+		}
+		if defaultClass == nil || defaultClass.CreationTimestamp.Before(&storageClass.CreationTimestamp) ||
+			(defaultClass.CreationTimestamp.Equal(&storageClass.CreationTimestamp) && storageClass.Name < defaultClass.Name) {
+			defaultClass = storageClass
+		}
+	}
+	if defaultClass == nil {
+		return "", nil
+	}
+	return defaultClass.Name, nil
+}
+
+// DetectIngressClasses returns the sorted names of the IngressClasses, which are empty if the cluster does not serve networking.k8s.io/v1
+func DetectIngressClasses(ctx context.Context, reader client.Reader) (interface{}, error) {
+	ingressClasses := &networkingv1.IngressClassList{}
+	if err := reader.List(ctx, ingressClasses); err != nil {
+		if isMissingAPI(err) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	names := make([]string, 0, len(ingressClasses.Items))
+	for _, ingressClass := range ingressClasses.Items {
+		names = append(names, ingressClass.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// DetectIngressDomain returns the default domain of the OpenShift ingress controller, or an empty string on other platforms
+func DetectIngressDomain(ctx context.Context, reader client.Reader) (interface{}, error) {
+	ingress := &unstructured.Unstructured{}
+	ingress.SetGroupVersionKind(schema.GroupVersionKind{Group: "config.openshift.io", Version: "v1", Kind: "Ingress"})
+	if err := reader.Get(ctx, client.ObjectKey{Name: "cluster"}, ingress); err != nil {
+		if isMissingAPI(err) {
+			return "", nil
+		}
+		return nil, err
+	}
+	domain, _, err := unstructured.NestedString(ingress.Object, "spec", "domain")
+	return domain, err
+}
+
+// DetectMultiArch returns true if the nodes of the cluster have more than one architecture, based on their kubernetes.io/arch label
+func DetectMultiArch(ctx context.Context, reader client.Reader) (interface{}, error) {
+	nodes := &metav1.PartialObjectMetadataList{}
+	nodes.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("NodeList"))
+	if err := reader.List(ctx, nodes); err != nil {
+		return nil, err
+	}
+	architectures := map[string]bool{}
+	for _, node := range nodes.Items {
+		if architecture := node.GetLabels()[corev1.LabelArchStable]; architecture != "" {
+			architectures[architecture] = true
+		}
+	}
+	return len(architectures) > 1, nil
+}
+
+// DetectFIPS returns true if the OpenShift cluster was installed with FIPS mode enabled, as recorded in its install config,
+// and false on other platforms. The install config is in the kube-system namespace, which operators are often not allowed to read,
+// so that a forbidden read returns nil, for unknown, rather than failing on each scan
+func DetectFIPS(ctx context.Context, reader client.Reader) (interface{}, error) {
+	clusterConfig := &corev1.ConfigMap{}
+	if err := reader.Get(ctx, client.ObjectKey{Namespace: "kube-system", Name: "cluster-config-v1"}, clusterConfig); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		if errors.IsForbidden(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	installConfig := struct {
+		FIPS bool `json:"fips"`
+	}{}
+	if err := yaml.Unmarshal([]byte(clusterConfig.Data["install-config"]), &installConfig); err != nil {
+		return nil, err
+	}
+	return installConfig.FIPS, nil
+}
+
+// isMissingAPI returns true if the error results from the cluster not serving the requested API, or the requested object not existing
+func isMissingAPI(err error) bool {
+	return meta.IsNoMatchError(err) || errors.IsNotFound(err)
+}
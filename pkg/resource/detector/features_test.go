@@ -0,0 +1,128 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/RHsyseng/operator-utils/pkg/test"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDetectorDetectsClusterFeatures(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.Nil(t, clientgoscheme.AddToScheme(scheme), "Expect no errors building scheme")
+	ingressConfig := &unstructured.Unstructured{}
+	ingressConfig.SetAPIVersion("config.openshift.io/v1")
+	ingressConfig.SetKind("Ingress")
+	ingressConfig.SetName("cluster")
+	assert.Nil(t, unstructured.SetNestedField(ingressConfig.Object, "apps.example.com", "spec", "domain"))
+	worker := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker", Labels: map[string]string{corev1.LabelArchStable: "amd64"}}}
+	reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "standard", Annotations: map[string]string{isDefaultStorageClassAnnotation: "true"}}},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "fast"}},
+		&networkingv1.IngressClass{ObjectMeta: metav1.ObjectMeta{Name: "openshift-default"}},
+		&networkingv1.IngressClass{ObjectMeta: metav1.ObjectMeta{Name: "nginx"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "master", Labels: map[string]string{corev1.LabelArchStable: "amd64"}}},
+		worker,
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-config-v1", Namespace: "kube-system"},
+			Data:       map[string]string{"install-config": "apiVersion: v1\nfips: true\nmetadata:\n  name: cluster\n"},
+		},
+		ingressConfig,
+	).Build()
+
+	d, err := NewAutoDetect(test.NewFakeDiscoveryBuilder().Build())
+	assert.Nil(t, err, "Expect no errors creating detector")
+	changes := map[string][]interface{}{}
+	d.WithFeatureReader(reader).AddClusterFeatures()
+	for _, name := range []string{FeatureDefaultStorageClass, FeatureMultiArch} {
+		d.AddFeatureTrigger(name, func(name string, value interface{}) {
+			changes[name] = append(changes[name], value)
+		})
+	}
+
+	assert.Nil(t, d.ScanNow(), "Expect no errors scanning")
+	defaultStorageClass, ok := GetFeature[string](d, FeatureDefaultStorageClass)
+	assert.True(t, ok, "Expect the default StorageClass to be evaluated")
+	assert.Equal(t, "standard", defaultStorageClass)
+	ingressClasses, _ := GetFeature[[]string](d, FeatureIngressClasses)
+	assert.Equal(t, []string{"nginx", "openshift-default"}, ingressClasses)
+	ingressDomain, _ := GetFeature[string](d, FeatureIngressDomain)
+	assert.Equal(t, "apps.example.com", ingressDomain)
+	multiArch, ok := GetFeature[bool](d, FeatureMultiArch)
+	assert.True(t, ok, "Expect multi-arch to be evaluated")
+	assert.False(t, multiArch, "Expect nodes of a single architecture not to be multi-arch")
+	fips, _ := GetFeature[bool](d, FeatureFIPS)
+	assert.True(t, fips, "Expect FIPS to be enabled")
+
+	worker.Labels[corev1.LabelArchStable] = "arm64"
+	assert.Nil(t, reader.Update(context.TODO(), worker), "Expect no errors updating node")
+	assert.Nil(t, d.ScanNow(), "Expect no errors scanning")
+	assert.Equal(t, []interface{}{"standard"}, changes[FeatureDefaultStorageClass], "Expect the trigger to run only when the value changes")
+	assert.Equal(t, []interface{}{false, true}, changes[FeatureMultiArch], "Expect the trigger to run when nodes become multi-arch")
+}
+
+func TestClusterFeaturesOnKubernetes(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.Nil(t, clientgoscheme.AddToScheme(scheme), "Expect no errors building scheme")
+	reader := fake.NewClientBuilder().WithScheme(scheme).Build()
+	for name, evaluate := range map[string]FeatureFunc{
+		FeatureDefaultStorageClass: DetectDefaultStorageClass,
+		FeatureIngressDomain:       DetectIngressDomain,
+		FeatureFIPS:                DetectFIPS,
+	} {
+		value, err := evaluate(context.TODO(), client.Reader(reader))
+		assert.Nil(t, err, "Expect no errors evaluating %s", name)
+		assert.Empty(t, value, "Expect %s to be empty on a cluster without it", name)
+	}
+}
+
+// forbiddenReader is not allowed to get ConfigMaps, like a reader without access to the kube-system namespace
+type forbiddenReader struct {
+	client.Reader
+}
+
+func (r forbiddenReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if _, ok := obj.(*corev1.ConfigMap); ok {
+		return errors.NewForbidden(corev1.Resource("configmaps"), key.Name, fmt.Errorf("access denied"))
+	}
+	return r.Reader.Get(ctx, key, obj, opts...)
+}
+
+func TestFIPSIsUnknownWhenForbidden(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.Nil(t, clientgoscheme.AddToScheme(scheme), "Expect no errors building scheme")
+	reader := forbiddenReader{Reader: fake.NewClientBuilder().WithScheme(scheme).Build()}
+
+	d, err := NewAutoDetect(test.NewFakeDiscoveryBuilder().Build())
+	assert.Nil(t, err, "Expect no errors creating detector")
+	d.WithFeatureReader(reader).AddClusterFeatures()
+	assert.Nil(t, d.ScanNow(), "Expect a forbidden install config not to fail the scan")
+	_, ok := GetFeature[bool](d, FeatureFIPS)
+	assert.False(t, ok, "Expect FIPS mode to be unknown")
+	_, ok = GetFeature[bool](d, FeatureMultiArch)
+	assert.True(t, ok, "Expect the other features to be evaluated")
+}
+
+func TestFeaturesRequireReader(t *testing.T) {
+	d, err := NewAutoDetect(test.NewFakeDiscoveryBuilder().Build())
+	assert.Nil(t, err, "Expect no errors creating detector")
+	assert.Nil(t, d.ScanNow(), "Expect no errors scanning without features")
+	d.AddFeature("custom", func(context.Context, client.Reader) (interface{}, error) {
+		return true, nil
+	})
+	assert.NotNil(t, d.ScanNow(), "Expect the scan to fail when features are registered without a reader")
+	_, ok := GetFeature[bool](d, "custom")
+	assert.False(t, ok, "Expect the feature not to be evaluated without a reader")
+}
@@ -0,0 +1,41 @@
+package detector
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	scanDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "operatorutils_detector_scan_duration_seconds",
+			Help:    "Duration of each scan of the API server for the CRDs registered with the detector",
+			Buckets: prometheus.DefBuckets,
+		},
+	)
+	detectedCRDs = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "operatorutils_detector_crd_detected",
+			Help: "Whether a CRD registered with the detector was found to exist (1) or not (0) by the last scan, by group, version and kind",
+		},
+		[]string{"group", "version", "kind"},
+	)
+	discoveryErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "operatorutils_detector_discovery_errors_total",
+			Help: "Number of failures to discover the resources of a group version, or to list the served groups when both are empty",
+		},
+		[]string{"group", "version"},
+	)
+	featureErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "operatorutils_detector_feature_errors_total",
+			Help: "Number of failures to evaluate a cluster feature registered with the detector",
+		},
+		[]string{"feature"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(scanDuration, detectedCRDs, discoveryErrors, featureErrors)
+}
@@ -1,6 +1,9 @@
 package detector
 
-import "sync"
+import (
+	"reflect"
+	"sync"
+)
 
 const (
 	RealmLabelSelectorsKey = "realmLabelSelectors"
@@ -8,20 +11,34 @@ const (
 
 type StateManager struct {
 	*sync.Mutex
-	state map[string]interface{}
+	state     map[string]interface{}
+	listeners map[string]map[int]StateListener
+	nextID    int
 }
 
+// StateListener is notified of the previous and new value, when the value of the key it subscribed to changes
+type StateListener func(key string, oldValue interface{}, newValue interface{})
+
 var singleton *StateManager
 var once sync.Once
 
+// GetStateManager returns the process-wide state manager, prefer NewStateManager for state that is not meant to be shared
 func GetStateManager() *StateManager {
 	once.Do(func() {
-		singleton = &StateManager{Mutex: &sync.Mutex{}}
-		singleton.state = make(map[string]interface{})
+		singleton = NewStateManager()
 	})
 	return singleton
 }
 
+// NewStateManager creates a state manager that is independent of the process-wide one
+func NewStateManager() *StateManager {
+	return &StateManager{
+		Mutex:     &sync.Mutex{},
+		state:     make(map[string]interface{}),
+		listeners: make(map[string]map[int]StateListener),
+	}
+}
+
 func (sm *StateManager) GetState(key string) interface{} {
 	sm.Lock()
 	defer sm.Unlock()
@@ -29,13 +46,78 @@ func (sm *StateManager) GetState(key string) interface{} {
 }
 
 func (sm *StateManager) SetState(key string, value interface{}) {
+	sm.SwapState(key, value)
+}
+
+// SwapState sets the value of the key and returns its previous value, atomically so that concurrent callers observe each transition once
+func (sm *StateManager) SwapState(key string, value interface{}) interface{} {
 	sm.Lock()
-	defer sm.Unlock()
+	previous := sm.state[key]
 	sm.state[key] = value
+	listeners := sm.getListeners(key)
+	sm.Unlock()
+	if !reflect.DeepEqual(previous, value) {
+		for _, listener := range listeners {
+			listener(key, previous, value)
+		}
+	}
+	return previous
 }
 
 func (sm *StateManager) Clear() {
 	sm.Lock()
-	defer sm.Unlock()
+	previous := sm.state
 	sm.state = make(map[string]interface{})
+	notifications := make(map[string][]StateListener)
+	for key, value := range previous {
+		if value != nil {
+			notifications[key] = sm.getListeners(key)
+		}
+	}
+	sm.Unlock()
+	for key, listeners := range notifications {
+		for _, listener := range listeners {
+			listener(key, previous[key], nil)
+		}
+	}
+}
+
+// Subscribe registers the listener to be notified when the value of the key changes, and returns a function to unsubscribe it
+// listeners are called synchronously by the goroutine that changes the state, after the state manager is unlocked
+func (sm *StateManager) Subscribe(key string, listener StateListener) func() {
+	sm.Lock()
+	defer sm.Unlock()
+	if sm.listeners[key] == nil {
+		sm.listeners[key] = make(map[int]StateListener)
+	}
+	id := sm.nextID
+	sm.nextID++
+	sm.listeners[key][id] = listener
+	return func() {
+		sm.Lock()
+		defer sm.Unlock()
+		delete(sm.listeners[key], id)
+	}
+}
+
+// getListeners returns a copy of the listeners of the key, and must be called while holding the lock
+func (sm *StateManager) getListeners(key string) []StateListener {
+	listeners := make([]StateListener, 0, len(sm.listeners[key]))
+	for _, listener := range sm.listeners[key] {
+		listeners = append(listeners, listener)
+	}
+	return listeners
+}
+
+// GetTypedState returns the value of the key as type T, and whether a value of that type is set
+// for example: selectors, ok := detector.GetTypedState[map[string]string](stateManager, detector.RealmLabelSelectorsKey)
+func GetTypedState[T any](sm *StateManager, key string) (T, bool) {
+	value, ok := sm.GetState(key).(T)
+	return value, ok
+}
+
+// GetBoolState returns the value of the key if it is a bool, or false otherwise, as used for the detection state of CRDs
+func (sm *StateManager) GetBoolState(key string) bool {
+	value, _ := GetTypedState[bool](sm, key)
+	return value
 }
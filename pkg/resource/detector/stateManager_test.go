@@ -1,6 +1,8 @@
 package detector
 
 import (
+	"fmt"
+	"reflect"
 	"testing"
 )
 
@@ -22,3 +24,39 @@ func TestStateManager_Test_(t *testing.T) {
 		t.Fatalf("Expected objects to be equal")
 	}
 }
+
+func TestStateManagerTypedAccessorsAndSubscriptions(t *testing.T) {
+	stateManager := NewStateManager()
+	if stateManager == GetStateManager() {
+		t.Fatalf("Expected a state manager independent of the singleton")
+	}
+
+	var changes []string
+	unsubscribe := stateManager.Subscribe("Test", func(key string, oldValue interface{}, newValue interface{}) {
+		changes = append(changes, fmt.Sprintf("%s:%v->%v", key, oldValue, newValue))
+	})
+	stateManager.SetState("Other", true)
+	stateManager.SetState("Test", true)
+	stateManager.SetState("Test", true)
+	if previous := stateManager.SwapState("Test", false); previous != true {
+		t.Fatalf("Expected previous value true, got '%v'", previous)
+	}
+	if stateManager.GetBoolState("Test") {
+		t.Fatalf("Expected false")
+	}
+	stateManager.SetState("Test", "string")
+	if _, ok := GetTypedState[bool](stateManager, "Test"); ok {
+		t.Fatalf("Expected a string value not to be returned as a bool")
+	}
+	if value, ok := GetTypedState[string](stateManager, "Test"); !ok || value != "string" {
+		t.Fatalf("Expected 'string' got '%s'", value)
+	}
+	stateManager.Clear()
+	unsubscribe()
+	stateManager.SetState("Test", true)
+
+	expected := []string{"Test:<nil>->true", "Test:true->false", "Test:false->string", "Test:string-><nil>"}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("Expected changes %v, got %v", expected, changes)
+	}
+}
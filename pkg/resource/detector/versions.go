@@ -0,0 +1,131 @@
+package detector
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// versionedKind holds the acceptable versions of a registered group and kind, in order of preference,
+// the triggers to run when the selected version changes, and the version that was last selected
+type versionedKind struct {
+	versions []string
+	triggers []trigger
+	version  string
+}
+
+// AddGroupKind registers a group and kind, along with its acceptable versions in order of preference, for example:
+// d.AddGroupKind(schema.GroupKind{Group: "policy", Kind: "PodDisruptionBudget"}, "v1", "v1beta1")
+// on each scan, the detector selects the served version, as reported by GetPreferredVersion
+func (d *Detector) AddGroupKind(groupKind schema.GroupKind, versions ...string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.getVersionedKind(groupKind).versions = versions
+}
+
+// AddGroupKindTrigger registers a group and kind like AddGroupKind, and runs the trigger function with an object of the selected
+// group, version and kind, the first time that a version is selected, and each time that the selected version changes
+func (d *Detector) AddGroupKindTrigger(groupKind schema.GroupKind, versions []string, trigger trigger) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	registration := d.getVersionedKind(groupKind)
+	registration.versions = versions
+	registration.triggers = append(registration.triggers, trigger)
+}
+
+// GetPreferredVersion returns the version selected for a registered group and kind, or an empty string if none of its acceptable versions is served.
+// The version preferred by the cluster for the group is selected if it is acceptable and serves the kind, otherwise the first acceptable version that serves the kind
+func (d *Detector) GetPreferredVersion(groupKind schema.GroupKind) string {
+	version, _ := GetTypedState[string](d.state, GroupKindStateKey(groupKind))
+	return version
+}
+
+// GroupKindStateKey returns the key under which the selected version of a group and kind is recorded, as a string
+func GroupKindStateKey(groupKind schema.GroupKind) string {
+	return "groupkind:" + groupKind.String()
+}
+
+// getVersionedKind returns the registration of the group and kind, and must be called while holding the mutex
+func (d *Detector) getVersionedKind(groupKind schema.GroupKind) *versionedKind {
+	registration, ok := d.versionedKinds[groupKind]
+	if !ok {
+		registration = &versionedKind{}
+		d.versionedKinds[groupKind] = registration
+	}
+	return registration
+}
+
+// detectVersions selects the served version of each registered group and kind, and runs the triggers of those whose selected version changed
+// a group and kind keeps its previously selected version when any of the versions that would take precedence cannot be discovered
+func (d *Detector) detectVersions(discovered *discoveryScan) {
+	d.mutex.Lock()
+	registrations := make(map[schema.GroupKind]versionedKind, len(d.versionedKinds))
+	for groupKind, registration := range d.versionedKinds {
+		registrations[groupKind] = *registration
+	}
+	d.mutex.Unlock()
+	if len(registrations) == 0 {
+		return
+	}
+	groups, ok := discovered.serverGroups()
+	if !ok {
+		return
+	}
+	for groupKind, registration := range registrations {
+		version, ok := selectVersion(discovered, groups, groupKind, registration.versions)
+		if !ok {
+			continue
+		}
+		d.state.SetState(GroupKindStateKey(groupKind), version)
+		if d.selectedVersionChanged(groupKind, version) && version != "" {
+			object := &metav1.PartialObjectMetadata{}
+			object.SetGroupVersionKind(groupKind.WithVersion(version))
+			for _, trigger := range registration.triggers {
+				trigger(object)
+			}
+		}
+	}
+}
+
+// selectedVersionChanged records the selected version of the group and kind, and returns true if it differs from the previously selected version
+func (d *Detector) selectedVersionChanged(groupKind schema.GroupKind, version string) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	registration := d.versionedKinds[groupKind]
+	if registration.version == version {
+		return false
+	}
+	registration.version = version
+	return true
+}
+
+func selectVersion(discovered *discoveryScan, groups *metav1.APIGroupList, groupKind schema.GroupKind, versions []string) (string, bool) {
+	candidates := versions
+	for _, group := range groups.Groups {
+		if group.Name == groupKind.Group {
+			candidates = append([]string{group.PreferredVersion.Version}, versions...)
+			break
+		}
+	}
+	for _, candidate := range candidates {
+		if !contains(versions, candidate) {
+			continue
+		}
+		served, known := discovered.servesKind(groupKind.WithVersion(candidate))
+		if !known {
+			return "", false
+		}
+		if served {
+			return candidate, true
+		}
+	}
+	return "", true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
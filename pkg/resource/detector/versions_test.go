@@ -0,0 +1,43 @@
+package detector
+
+import (
+	"testing"
+
+	"github.com/RHsyseng/operator-utils/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestDetectorSelectsPreferredVersion(t *testing.T) {
+	dc := test.NewFakeDiscoveryBuilder().Build()
+	d, err := NewAutoDetect(dc)
+	assert.Nil(t, err, "Expect no errors creating detector")
+
+	pdb := schema.GroupKind{Group: "policy", Kind: "PodDisruptionBudget"}
+	hpa := schema.GroupKind{Group: "autoscaling", Kind: "HorizontalPodAutoscaler"}
+	var selected []schema.GroupVersionKind
+	d.AddGroupKindTrigger(pdb, []string{"v1", "v1beta1"}, func(object runtime.Object) {
+		selected = append(selected, object.GetObjectKind().GroupVersionKind())
+	})
+	d.AddGroupKind(hpa, "v2", "v1")
+
+	assert.Nil(t, d.ScanNow(), "Expect no errors scanning")
+	assert.Empty(t, d.GetPreferredVersion(pdb), "Expect no version while the group is not served")
+	assert.Empty(t, selected, "Expect no trigger while the group is not served")
+
+	//the cluster prefers a version of autoscaling that is not acceptable
+	dc.Install(pdb.WithVersion("v1beta1"), hpa.WithVersion("v2beta2"), hpa.WithVersion("v1"))
+	assert.Nil(t, d.ScanNow(), "Expect no errors scanning")
+	assert.Equal(t, "v1beta1", d.GetPreferredVersion(pdb), "Expect the only served version to be selected")
+	assert.Equal(t, "v1", d.GetPreferredVersion(hpa), "Expect the first acceptable served version to be selected")
+
+	//the fake discovery prefers the first installed version of a group
+	dc.Uninstall(pdb.WithVersion("v1beta1"))
+	dc.Install(pdb.WithVersion("v1"), pdb.WithVersion("v1beta1"))
+	assert.Nil(t, d.ScanNow(), "Expect no errors scanning")
+	assert.Nil(t, d.ScanNow(), "Expect no errors scanning")
+	assert.Equal(t, "v1", d.GetPreferredVersion(pdb), "Expect the version preferred by the cluster to be selected")
+	assert.Equal(t, []schema.GroupVersionKind{pdb.WithVersion("v1beta1"), pdb.WithVersion("v1")}, selected,
+		"Expect the trigger to run each time the selected version changes")
+}
@@ -0,0 +1,114 @@
+package detector
+
+import (
+	"context"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// WithCRDWatch allows the detector to watch CustomResourceDefinitions through the provided client, and run triggers as soon as
+// a registered CRD is established, rather than at the next scan. The scheme of the client must include apiextensions.k8s.io/v1.
+// Periodic scans continue as a fallback, and remain the only means of detection if the operator is not allowed to list CRDs
+func (d *Detector) WithCRDWatch(watchClient client.WithWatch) *Detector {
+	d.watchClient = watchClient
+	return d
+}
+
+func (d *Detector) startCRDWatch(ctx context.Context) {
+	err := d.watchClient.List(ctx, &apiextensionsv1.CustomResourceDefinitionList{}, client.Limit(1))
+	if err != nil {
+		logger.Info("Cannot list CRDs, detection will rely on periodic scans", "error", err.Error())
+		return
+	}
+	listWatch := &toolscache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			list := &apiextensionsv1.CustomResourceDefinitionList{}
+			err := d.watchClient.List(ctx, list, &client.ListOptions{Raw: &options})
+			return list, err
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return d.watchClient.Watch(ctx, &apiextensionsv1.CustomResourceDefinitionList{}, &client.ListOptions{Raw: &options})
+		},
+	}
+	informer := toolscache.NewSharedIndexInformer(listWatch, &apiextensionsv1.CustomResourceDefinition{}, 0, toolscache.Indexers{})
+	_, err = informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc: d.onCRDEvent,
+		UpdateFunc: func(_, newObj interface{}) {
+			d.onCRDEvent(newObj)
+		},
+		DeleteFunc: d.onCRDDeleted,
+	})
+	if err != nil {
+		logger.Error(err, "Failed to watch CRDs, detection will rely on periodic scans")
+		return
+	}
+	go informer.Run(ctx.Done())
+}
+
+// onCRDEvent runs the triggers of the registered CRDs that the established CRD serves,
+// as well as the removed triggers of registered CRDs whose version is no longer served.
+// Events are handled while holding the scan mutex, so that a scan cannot interleave with them
+func (d *Detector) onCRDEvent(obj interface{}) {
+	crd, ok := obj.(*apiextensionsv1.CustomResourceDefinition)
+	if !ok || !isEstablished(crd) {
+		return
+	}
+	d.scanMutex.Lock()
+	defer d.scanMutex.Unlock()
+	for _, registered := range d.getCRDs() {
+		gvk := registered.GetObjectKind().GroupVersionKind()
+		if crd.Spec.Group != gvk.Group || crd.Spec.Names.Kind != gvk.Kind {
+			continue
+		}
+		served := servesVersion(crd, gvk.Version)
+		d.setWatched(registered, served)
+		if served {
+			d.setDetected(registered)
+		} else {
+			d.setRemoved(registered)
+		}
+	}
+}
+
+// onCRDDeleted runs the removed triggers of the registered CRDs that the deleted CRD defined
+func (d *Detector) onCRDDeleted(obj interface{}) {
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	crd, ok := obj.(*apiextensionsv1.CustomResourceDefinition)
+	if !ok {
+		return
+	}
+	d.scanMutex.Lock()
+	defer d.scanMutex.Unlock()
+	for _, registered := range d.getCRDs() {
+		gvk := registered.GetObjectKind().GroupVersionKind()
+		if crd.Spec.Group == gvk.Group && crd.Spec.Names.Kind == gvk.Kind {
+			d.setWatched(registered, false)
+			d.setRemoved(registered)
+		}
+	}
+}
+
+func isEstablished(crd *apiextensionsv1.CustomResourceDefinition) bool {
+	for _, condition := range crd.Status.Conditions {
+		if condition.Type == apiextensionsv1.Established {
+			return condition.Status == apiextensionsv1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func servesVersion(crd *apiextensionsv1.CustomResourceDefinition, version string) bool {
+	for _, crdVersion := range crd.Spec.Versions {
+		if crdVersion.Name == version && crdVersion.Served {
+			return true
+		}
+	}
+	return false
+}
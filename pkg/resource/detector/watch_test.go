@@ -0,0 +1,117 @@
+package detector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RHsyseng/operator-utils/pkg/test"
+	"github.com/stretchr/testify/assert"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDetectorWatchesCRDs(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.Nil(t, apiextensionsv1.AddToScheme(scheme), "Expect no errors building scheme")
+	watchClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	dc := test.NewFakeDiscoveryBuilder().Build()
+
+	d, err := NewAutoDetect(dc)
+	assert.Nil(t, err, "Expect no errors creating detector")
+	detected := make(chan runtime.Object, 1)
+	removed := make(chan runtime.Object, 1)
+	serviceMonitor := &metav1.PartialObjectMetadata{
+		TypeMeta: metav1.TypeMeta{Kind: "ServiceMonitor", APIVersion: "monitoring.coreos.com/v1"},
+	}
+	d.AddCRDTrigger(serviceMonitor, func(crd runtime.Object) {
+		detected <- crd
+	})
+	d.AddCRDRemovedTrigger(serviceMonitor, func(crd runtime.Object) {
+		removed <- crd
+	})
+	//Scan rarely, so that detection can only result from the watch
+	go d.WithCRDWatch(watchClient).WithInterval(time.Hour).Start(context.TODO())
+	defer d.Stop()
+
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "servicemonitors.monitoring.coreos.com"},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group:    "monitoring.coreos.com",
+			Names:    apiextensionsv1.CustomResourceDefinitionNames{Kind: "ServiceMonitor", Plural: "servicemonitors"},
+			Scope:    apiextensionsv1.NamespaceScoped,
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Name: "v1", Served: true, Storage: true}},
+		},
+	}
+	assert.Nil(t, watchClient.Create(context.TODO(), crd), "Expect no errors creating CRD")
+	select {
+	case <-detected:
+		t.Fatalf("CRD detected before it was established")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	crd.Status.Conditions = []apiextensionsv1.CustomResourceDefinitionCondition{{Type: apiextensionsv1.Established, Status: apiextensionsv1.ConditionTrue}}
+	assert.Nil(t, watchClient.Update(context.TODO(), crd), "Expect no errors updating CRD")
+	select {
+	case <-detected:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("CRD not detected through the watch")
+	}
+
+	assert.Nil(t, watchClient.Delete(context.TODO(), crd), "Expect no errors deleting CRD")
+	select {
+	case <-removed:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("CRD removal not detected through the watch")
+	}
+}
+
+func TestDetectorKeepsWatchedStateUntilDiscoveryAgrees(t *testing.T) {
+	dc := test.NewFakeDiscoveryBuilder().Build()
+	d, err := NewAutoDetect(dc)
+	assert.Nil(t, err, "Expect no errors creating detector")
+	var events []string
+	gvk := schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}
+	serviceMonitor := &metav1.PartialObjectMetadata{}
+	serviceMonitor.SetGroupVersionKind(gvk)
+	d.AddCRDTrigger(serviceMonitor, func(runtime.Object) {
+		events = append(events, "added")
+	})
+	d.AddCRDRemovedTrigger(serviceMonitor, func(runtime.Object) {
+		events = append(events, "removed")
+	})
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group:    gvk.Group,
+			Names:    apiextensionsv1.CustomResourceDefinitionNames{Kind: gvk.Kind},
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Name: gvk.Version, Served: true}},
+		},
+		Status: apiextensionsv1.CustomResourceDefinitionStatus{
+			Conditions: []apiextensionsv1.CustomResourceDefinitionCondition{{Type: apiextensionsv1.Established, Status: apiextensionsv1.ConditionTrue}},
+		},
+	}
+
+	//the watch reports the established CRD before discovery serves it
+	d.onCRDEvent(crd)
+	assert.Nil(t, d.ScanNow(), "Expect no errors scanning")
+	assert.Equal(t, []string{"added"}, events, "Expect stale discovery not to undo the detection of the watch")
+	assert.True(t, d.IsCRDDetected(gvk), "Expect the CRD to remain detected while discovery is stale")
+
+	//once discovery agrees, it prevails again
+	dc.Install(gvk)
+	assert.Nil(t, d.ScanNow(), "Expect no errors scanning")
+	dc.Uninstall(gvk)
+	assert.Nil(t, d.ScanNow(), "Expect no errors scanning")
+	assert.Equal(t, []string{"added", "removed"}, events, "Expect discovery to detect the removal once it agreed with the watch")
+
+	//the watch reports the deleted CRD before discovery stops serving it
+	dc.Install(gvk)
+	assert.Nil(t, d.ScanNow(), "Expect no errors scanning")
+	d.onCRDDeleted(crd)
+	assert.Nil(t, d.ScanNow(), "Expect no errors scanning")
+	assert.Equal(t, []string{"added", "removed", "added", "removed"}, events, "Expect stale discovery not to undo the removal reported by the watch")
+	assert.False(t, d.IsCRDDetected(gvk), "Expect the CRD to remain removed while discovery is stale")
+}
@@ -0,0 +1,127 @@
+package read
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// WithFallbackReader allows reading from the informer cache, typically provided to New, while falling back to the provided
+// uncached API reader when an object is not found in the cache, or the cache is not yet started.
+// Objects of the same group and kind as any of the provided uncachedObjects are always read from the API reader, whether they are
+// read as typed, unstructured or metadata-only objects, which avoids creating informers, and caching every instance in memory,
+// for large kinds like Secrets. Kinds that the cache is not already watching are not detected, as the cache offers no way to check
+// for an informer without starting one, so that reading any other kind through the cache starts an informer for it, and only kinds
+// listed as uncachedObjects are guaranteed not to be cached. Cache hits and misses are reported through the
+// operatorutils_reader_requests_total metric
+func (this *resourceReader) WithFallbackReader(apiReader client.Reader, uncachedObjects ...client.Object) *resourceReader {
+	this.apiReader = apiReader
+	this.uncachedObjects = uncachedObjects
+	return this
+}
+
+// WithScheme sets the scheme used to resolve the kind of typed objects, which otherwise defaults to the scheme of the reader
+// provided to New or to WithFallbackReader, such as the client of a manager, or to the client-go scheme
+func (this *resourceReader) WithScheme(scheme *runtime.Scheme) *resourceReader {
+	this.scheme = scheme
+	return this
+}
+
+func (this *resourceReader) get(ctx context.Context, key types.NamespacedName, object client.Object) error {
+	if this.apiReader == nil {
+		return this.reader.Get(ctx, key, object)
+	}
+	gvk := this.getKind(object)
+	if this.isUncached(gvk) {
+		readerRequests.WithLabelValues(gvk.Kind, resultUncached).Inc()
+		return this.apiReader.Get(ctx, key, object)
+	}
+	err := this.reader.Get(ctx, key, object)
+	if err == nil {
+		readerRequests.WithLabelValues(gvk.Kind, resultHit).Inc()
+		return nil
+	}
+	if !isCacheMiss(err) {
+		return err
+	}
+	logger.V(1).Info("Object not found in cache, reading from API", "kind", gvk.Kind, "name", key.Name, "reason", err.Error())
+	readerRequests.WithLabelValues(gvk.Kind, resultMiss).Inc()
+	return this.apiReader.Get(ctx, key, object)
+}
+
+func (this *resourceReader) list(ctx context.Context, listObject client.ObjectList, opts ...client.ListOption) error {
+	if this.apiReader == nil {
+		return this.reader.List(ctx, listObject, opts...)
+	}
+	//An empty list is a valid result, so only a cache that cannot serve the type causes a fallback
+	gvk := this.getKind(listObject)
+	gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+	if this.isUncached(gvk) {
+		readerRequests.WithLabelValues(gvk.Kind, resultUncached).Inc()
+		return this.apiReader.List(ctx, listObject, opts...)
+	}
+	err := this.reader.List(ctx, listObject, opts...)
+	if err == nil {
+		readerRequests.WithLabelValues(gvk.Kind, resultHit).Inc()
+		return nil
+	}
+	if !isCacheUnavailable(err) {
+		return err
+	}
+	logger.V(1).Info("Objects not available in cache, listing from API", "kind", gvk.Kind, "reason", err.Error())
+	readerRequests.WithLabelValues(gvk.Kind, resultMiss).Inc()
+	return this.apiReader.List(ctx, listObject, opts...)
+}
+
+// isUncached returns true if the group and kind are those of one of the objects that are always read from the API reader
+func (this *resourceReader) isUncached(gvk schema.GroupVersionKind) bool {
+	for _, object := range this.uncachedObjects {
+		if this.getKind(object).GroupKind() == gvk.GroupKind() {
+			return true
+		}
+	}
+	return false
+}
+
+// getKind returns the kind that is set on unstructured and metadata-only objects, or that is registered in the scheme for typed objects,
+// and falls back to the name of the type of objects that are not registered
+func (this *resourceReader) getKind(object runtime.Object) schema.GroupVersionKind {
+	gvk, err := apiutil.GVKForObject(object, this.getScheme())
+	if err != nil {
+		return schema.GroupVersionKind{Kind: reflect.Indirect(reflect.ValueOf(object)).Type().Name()}
+	}
+	return gvk
+}
+
+func (this *resourceReader) getScheme() *runtime.Scheme {
+	if this.scheme != nil {
+		return this.scheme
+	}
+	for _, reader := range []client.Reader{this.reader, this.apiReader} {
+		if schemeReader, ok := reader.(interface{ Scheme() *runtime.Scheme }); ok {
+			return schemeReader.Scheme()
+		}
+	}
+	return scheme.Scheme
+}
+
+// isCacheMiss returns true if the error indicates that the object may exist, even though the cache could not return it
+func isCacheMiss(err error) bool {
+	return client.IgnoreNotFound(err) == nil || isCacheUnavailable(err)
+}
+
+// isCacheUnavailable returns true if the error indicates that the cache cannot serve objects of the requested kind
+func isCacheUnavailable(err error) bool {
+	var notStarted *cache.ErrCacheNotStarted
+	return errors.As(err, &notStarted) || meta.IsNoMatchError(err)
+}
@@ -0,0 +1,116 @@
+package read
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestFallbackReaderLoad(t *testing.T) {
+	scheme := runtime.NewScheme()
+	err := corev1.SchemeBuilder.AddToScheme(scheme)
+	assert.Nil(t, err, "Expect no errors building scheme")
+	services := getServices(2)
+	services[0].ResourceVersion = ""
+	services[1].ResourceVersion = ""
+	secret := &corev1.Secret{ObjectMeta: v1.ObjectMeta{Name: "secret", Namespace: namespace}}
+	cacheReader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(services[0].DeepCopy()).Build()
+	apiReader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(services[0].DeepCopy(), services[1].DeepCopy(), secret).Build()
+
+	reader := New(cacheReader).WithNamespace(namespace).WithFallbackReader(apiReader, &corev1.Secret{})
+	hits := testutil.ToFloat64(readerRequests.WithLabelValues("Service", resultHit))
+	misses := testutil.ToFloat64(readerRequests.WithLabelValues("Service", resultMiss))
+	uncached := testutil.ToFloat64(readerRequests.WithLabelValues("Secret", resultUncached))
+
+	_, err = LoadTyped[corev1.Service](reader, services[0].Name)
+	assert.Nil(t, err, "Expect service to be found in cache")
+	_, err = LoadTyped[corev1.Service](reader, services[1].Name)
+	assert.Nil(t, err, "Expect service missing from cache to be found through the API reader")
+	_, err = LoadTyped[corev1.Service](reader, "missing")
+	assert.True(t, errors.IsNotFound(err), "Expect a not-found error for a service missing from both readers")
+	_, err = LoadTyped[corev1.Secret](reader, secret.Name)
+	assert.Nil(t, err, "Expect uncached secret to be found through the API reader")
+
+	assert.Equal(t, hits+1, testutil.ToFloat64(readerRequests.WithLabelValues("Service", resultHit)), "Expect one cache hit")
+	assert.Equal(t, misses+2, testutil.ToFloat64(readerRequests.WithLabelValues("Service", resultMiss)), "Expect two cache misses")
+	assert.Equal(t, uncached+1, testutil.ToFloat64(readerRequests.WithLabelValues("Secret", resultUncached)), "Expect one uncached read")
+}
+
+func TestFallbackReaderList(t *testing.T) {
+	scheme := runtime.NewScheme()
+	err := corev1.SchemeBuilder.AddToScheme(scheme)
+	assert.Nil(t, err, "Expect no errors building scheme")
+	services := getServices(2)
+	services[0].ResourceVersion = ""
+	services[1].ResourceVersion = ""
+	apiReader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&services[0], &services[1]).Build()
+
+	reader := New(&notStartedReader{}).WithNamespace(namespace).WithFallbackReader(apiReader)
+	listed, err := ListTyped[corev1.Service, corev1.ServiceList](reader)
+	assert.Nil(t, err, "Expect services to be listed through the API reader when the cache is not started")
+	assert.Len(t, listed, 2, "Expect to find 2 services")
+
+	_, err = New(&notStartedReader{}).WithNamespace(namespace).List(&corev1.ServiceList{})
+	assert.NotNil(t, err, "Expect the cache error to be returned without a fallback reader")
+}
+
+func TestFallbackReaderUncachedKinds(t *testing.T) {
+	scheme := runtime.NewScheme()
+	err := corev1.SchemeBuilder.AddToScheme(scheme)
+	assert.Nil(t, err, "Expect no errors building scheme")
+	secrets := getSecrets(2)
+	apiReader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&secrets[0], &secrets[1]).Build()
+	cacheReader := &countingReader{}
+	secretKind := corev1.SchemeGroupVersion.WithKind("Secret")
+
+	reader := New(cacheReader).WithNamespace(namespace).WithScheme(scheme).WithFallbackReader(apiReader, &corev1.Secret{})
+	uncached := testutil.ToFloat64(readerRequests.WithLabelValues("Secret", resultUncached))
+	listed, err := reader.ListMetadata(secretKind)
+	assert.Nil(t, err, "Expect no errors listing metadata")
+	assert.Len(t, listed, 2, "Expect to find 2 secrets")
+	listed, err = reader.ListGVK(secretKind)
+	assert.Nil(t, err, "Expect no errors listing unstructured objects")
+	assert.Len(t, listed, 2, "Expect to find 2 secrets")
+	_, err = reader.LoadGVK(secretKind, secrets[0].Name)
+	assert.Nil(t, err, "Expect no errors loading an unstructured object")
+	_, err = reader.List(&corev1.SecretList{})
+	assert.Nil(t, err, "Expect no errors listing typed objects")
+
+	assert.Zero(t, cacheReader.requests, "Expect an uncached kind never to be read from the cache, whatever the type of object")
+	assert.Equal(t, uncached+4, testutil.ToFloat64(readerRequests.WithLabelValues("Secret", resultUncached)), "Expect uncached reads to be reported by kind")
+}
+
+// countingReader counts the requests made to it, and fails them as a cache that is not started
+type countingReader struct {
+	notStartedReader
+	requests int
+}
+
+func (this *countingReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	this.requests++
+	return this.notStartedReader.Get(ctx, key, obj, opts...)
+}
+
+func (this *countingReader) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	this.requests++
+	return this.notStartedReader.List(ctx, list, opts...)
+}
+
+type notStartedReader struct{}
+
+func (this *notStartedReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	return &cache.ErrCacheNotStarted{}
+}
+
+func (this *notStartedReader) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return &cache.ErrCacheNotStarted{}
+}
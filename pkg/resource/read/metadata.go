@@ -0,0 +1,82 @@
+package read
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/RHsyseng/operator-utils/pkg/resource/compare"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ListMetadata returns metadata-only objects of the provided kind, based on the configured namespace and owner, without reading their
+// content, which may be large for kinds like Secrets and ConfigMaps. Metadata is sufficient to find added objects and to remove objects,
+// while CompareMetadata only loads in full the objects that also need to be compared
+func (this *resourceReader) ListMetadata(gvk schema.GroupVersionKind) ([]client.Object, error) {
+	listObject := &metav1.PartialObjectMetadataList{}
+	listObject.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	resources, err := this.List(listObject)
+	if err != nil {
+		return nil, err
+	}
+	for _, resource := range resources {
+		//The kind of each item is required to later load or remove it
+		resource.GetObjectKind().SetGroupVersionKind(gvk)
+	}
+	return resources, nil
+}
+
+// CompareMetadata returns the delta between the deployed objects, typically the metadata-only objects from ListMetadata, and the
+// requested ones. Objects are added and removed based on their metadata alone, while a deployed object with a requested counterpart
+// of the same namespace and name is only loaded in full, as the type of the requested object, when the comparator is about to compare
+// them, and is not retained, so that the content of a large list is never held in memory at once. A deployed object that no longer
+// exists by the time it is loaded is deemed absent, so that its requested counterpart is added, and any other error is directly returned
+func (this *resourceReader) CompareMetadata(comparator compare.ResourceComparator, deployed []client.Object, requested []client.Object) (compare.ResourceDelta, error) {
+	delta := compare.ResourceDelta{}
+	deployedMap := getObjectKeyMap(deployed)
+	requestedMap := getObjectKeyMap(requested)
+	for _, object := range requested {
+		counterpart := deployedMap[client.ObjectKeyFromObject(object)]
+		if counterpart == nil {
+			delta.Added = append(delta.Added, object)
+			continue
+		}
+		loaded, err := this.loadAs(counterpart, object)
+		if errors.IsNotFound(err) {
+			delta.Added = append(delta.Added, object)
+			continue
+		} else if err != nil {
+			return compare.ResourceDelta{}, err
+		}
+		if !comparator.Compare(loaded, object) {
+			delta.Updated = append(delta.Updated, object)
+		}
+	}
+	for _, object := range deployed {
+		if requestedMap[client.ObjectKeyFromObject(object)] == nil {
+			delta.Removed = append(delta.Removed, object)
+		}
+	}
+	return delta, nil
+}
+
+// loadAs returns the deployed object loaded in full as the type of the requested object, or as it is if it is already of that type
+func (this *resourceReader) loadAs(deployed client.Object, requested client.Object) (client.Object, error) {
+	requestedType := reflect.TypeOf(requested)
+	if reflect.TypeOf(deployed) == requestedType {
+		return deployed, nil
+	}
+	loaded := reflect.New(requestedType.Elem()).Interface().(client.Object)
+	err := this.get(context.TODO(), client.ObjectKeyFromObject(deployed), loaded)
+	return loaded, err
+}
+
+func getObjectKeyMap(objects []client.Object) map[client.ObjectKey]client.Object {
+	objectMap := make(map[client.ObjectKey]client.Object, len(objects))
+	for _, object := range objects {
+		objectMap[client.ObjectKeyFromObject(object)] = object
+	}
+	return objectMap
+}
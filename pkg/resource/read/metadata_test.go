@@ -0,0 +1,87 @@
+package read
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/RHsyseng/operator-utils/pkg/resource/compare"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestListMetadata(t *testing.T) {
+	scheme := runtime.NewScheme()
+	err := corev1.SchemeBuilder.AddToScheme(scheme)
+	assert.Nil(t, err, "Expect no errors building scheme")
+	secrets := getSecrets(3)
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&secrets[0], &secrets[1], &secrets[2]).Build()
+
+	reader := New(fakeClient).WithNamespace(namespace)
+	listed, err := reader.ListMetadata(corev1.SchemeGroupVersion.WithKind("Secret"))
+	assert.Nil(t, err, "Expect no errors listing metadata")
+	assert.Len(t, listed, 3, "Expect to find 3 secrets")
+	for index := range listed {
+		metadata, ok := listed[index].(*v1.PartialObjectMetadata)
+		assert.True(t, ok, "Expect metadata-only objects")
+		assert.Equal(t, secrets[index].Name, metadata.Name)
+		assert.Equal(t, "Secret", metadata.Kind)
+	}
+	assert.Nil(t, fakeClient.Delete(context.TODO(), listed[2]), "Expect metadata-only objects to be removable")
+}
+
+func TestCompareMetadata(t *testing.T) {
+	scheme := runtime.NewScheme()
+	err := corev1.SchemeBuilder.AddToScheme(scheme)
+	assert.Nil(t, err, "Expect no errors building scheme")
+	secrets := getSecrets(4)
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&secrets[0], &secrets[1], &secrets[2], &secrets[3]).Build()
+	getRecorder := &getRecorder{Reader: fakeClient}
+
+	reader := New(getRecorder).WithNamespace(namespace)
+	listed, err := reader.ListMetadata(corev1.SchemeGroupVersion.WithKind("Secret"))
+	assert.Nil(t, err, "Expect no errors listing metadata")
+	//secret-1 is unchanged, secret-2 is changed, secret-3 is no longer requested, and secret-4 is deleted once listed
+	requested := getSecrets(4)
+	requested[1].Data["key"] = []byte("changed")
+	requested[2].Name = "new-secret"
+	assert.Nil(t, fakeClient.Delete(context.TODO(), &secrets[3]), "Expect no errors deleting secret")
+
+	delta, err := reader.CompareMetadata(compare.DefaultComparator(), listed, []client.Object{&requested[0], &requested[1], &requested[2], &requested[3]})
+	assert.Nil(t, err, "Expect no errors comparing metadata")
+	assert.Equal(t, []client.Object{&requested[2], &requested[3]}, delta.Added, "Expect new and concurrently deleted secrets to be added")
+	assert.Equal(t, []client.Object{&requested[1]}, delta.Updated, "Expect the changed secret to be updated")
+	assert.Len(t, delta.Removed, 1, "Expect the secret that is no longer requested to be removed")
+	assert.IsType(t, &v1.PartialObjectMetadata{}, delta.Removed[0], "Expect the removed secret to remain metadata-only")
+	assert.Equal(t, "secret-3", delta.Removed[0].GetName())
+	assert.Equal(t, []string{"secret-1", "secret-2", "secret-4"}, getRecorder.names, "Expect only the secrets with a requested counterpart to be loaded")
+}
+
+// getRecorder records the names of the objects that are loaded through it
+type getRecorder struct {
+	client.Reader
+	names []string
+}
+
+func (this *getRecorder) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	this.names = append(this.names, key.Name)
+	return this.Reader.Get(ctx, key, obj, opts...)
+}
+
+func getSecrets(count int) []corev1.Secret {
+	secrets := make([]corev1.Secret, count)
+	for index := range secrets {
+		secrets[index] = corev1.Secret{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      fmt.Sprintf("secret-%d", index+1),
+				Namespace: namespace,
+			},
+			Data: map[string][]byte{"key": []byte(fmt.Sprintf("value-%d", index+1))},
+		}
+	}
+	return secrets
+}
@@ -0,0 +1,25 @@
+package read
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	resultHit      = "hit"
+	resultMiss     = "miss"
+	resultUncached = "uncached"
+)
+
+var readerRequests = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "operatorutils_reader_requests_total",
+		Help: "Number of reads made with a fallback reader, by kind and by whether they were served by the cache (hit), " +
+			"fell back to the API after the cache failed to serve them (miss), or were always read from the API (uncached)",
+	},
+	[]string{"kind", "result"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(readerRequests)
+}
@@ -2,16 +2,25 @@ package read
 
 import (
 	"context"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"reflect"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+var logger = ctrl.Log.WithName("reader")
+
 type resourceReader struct {
-	reader      client.Reader
-	namespace   string
-	ownerObject metav1.Object
+	reader          client.Reader
+	apiReader       client.Reader
+	uncachedObjects []client.Object
+	scheme          *runtime.Scheme
+	mapper          meta.RESTMapper
+	namespace       string
+	ownerObject     metav1.Object
 }
 
 // New creates a resourceReader object that can be used to load/list kubernetes resources
@@ -36,7 +45,7 @@ func (this *resourceReader) WithOwnerObject(ownerObject metav1.Object) *resource
 // any error from underlying calls is directly returned as well
 func (this *resourceReader) List(listObject client.ObjectList) ([]client.Object, error) {
 	var resources []client.Object
-	err := this.reader.List(context.TODO(), listObject, &client.ListOptions{Namespace: this.namespace})
+	err := this.list(context.TODO(), listObject, &client.ListOptions{Namespace: this.namespace})
 	if err != nil {
 		return nil, err
 	}
@@ -88,6 +97,6 @@ func (this *resourceReader) ListAll(listObjects ...client.ObjectList) (map[refle
 // any error from the underlying call, including a not-found error, is directly returned as well
 func (this *resourceReader) Load(resourceType reflect.Type, name string) (client.Object, error) {
 	deployed := reflect.New(resourceType).Interface().(client.Object)
-	err := this.reader.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: this.namespace}, deployed)
+	err := this.get(context.TODO(), types.NamespacedName{Name: name, Namespace: this.namespace}, deployed)
 	return deployed, err
 }
@@ -78,6 +78,44 @@ func TestLoadObject(t *testing.T) {
 	assert.Equal(t, &service, found)
 }
 
+func TestListTyped(t *testing.T) {
+	scheme := runtime.NewScheme()
+	err := corev1.SchemeBuilder.AddToScheme(scheme)
+	assert.Nil(t, err, "Expect no errors building scheme")
+	client := fake.NewFakeClientWithScheme(scheme)
+	services := getServices(2)
+	for index := range services {
+		services[index].ResourceVersion = ""
+		assert.Nil(t, client.Create(context.TODO(), &services[index]), "Expect no errors mock creating objects")
+	}
+
+	reader := New(client).WithNamespace(namespace)
+	listedServices, err := ListTyped[corev1.Service, corev1.ServiceList](reader)
+	assert.Nil(t, err, "Expect no errors listing objects")
+	expectedServices := getServices(2)
+	assert.Equal(t, []*corev1.Service{&expectedServices[0], &expectedServices[1]}, listedServices)
+
+	_, err = ListTyped[corev1.Pod, corev1.ServiceList](reader)
+	assert.NotNil(t, err, "Expect an error listing items of a mismatched type")
+	_, err = ListTyped[corev1.Pod, corev1.ServiceList](New(client).WithNamespace("empty"))
+	assert.NotNil(t, err, "Expect an error listing items of a mismatched type, even when the list is empty")
+}
+
+func TestLoadTyped(t *testing.T) {
+	scheme := runtime.NewScheme()
+	err := corev1.SchemeBuilder.AddToScheme(scheme)
+	assert.Nil(t, err, "Expect no errors building scheme")
+	client := fake.NewFakeClientWithScheme(scheme)
+	service := getServices(1)[0]
+	service.ResourceVersion = ""
+	assert.Nil(t, client.Create(context.TODO(), &service), "Expect no errors mock creating object")
+
+	reader := New(client).WithNamespace(namespace)
+	found, err := LoadTyped[corev1.Service](reader, service.Name)
+	assert.Nil(t, err, "Expect no errors loading object")
+	assert.Equal(t, &service, found)
+}
+
 func getServices(count int) []corev1.Service {
 	services := make([]corev1.Service, count)
 	for index := range services {
@@ -0,0 +1,45 @@
+package read
+
+import (
+	"fmt"
+	"reflect"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ListTyped returns the Kubernetes resources of type T, listed through a list of type L, based on the reader configuration
+// for example: services, err := read.ListTyped[corev1.Service, corev1.ServiceList](reader)
+// types that are not Kubernetes objects or lists are rejected at compile time, while an error is returned before listing if the items
+// of L are not of type T
+func ListTyped[T any, L any, PT interface {
+	*T
+	client.Object
+}, PL interface {
+	*L
+	client.ObjectList
+}](reader *resourceReader) ([]PT, error) {
+	listType := reflect.TypeOf(*new(L))
+	itemType := reflect.TypeOf(*new(T))
+	if items, found := listType.FieldByName("Items"); !found || items.Type.Kind() != reflect.Slice || items.Type.Elem() != itemType {
+		return nil, fmt.Errorf("list type %v does not hold items of type %v", listType, itemType)
+	}
+	resources, err := reader.List(PL(new(L)))
+	if err != nil {
+		return nil, err
+	}
+	items := make([]PT, 0, len(resources))
+	for _, resource := range resources {
+		items = append(items, resource.(PT))
+	}
+	return items, nil
+}
+
+// LoadTyped returns the object of type T with the given name, in the previously configured namespace
+// any error from the underlying call, including a not-found error, is directly returned as well
+func LoadTyped[T any, PT interface {
+	*T
+	client.Object
+}](reader *resourceReader, name string) (PT, error) {
+	deployed, err := reader.Load(reflect.TypeOf(*new(T)), name)
+	return deployed.(PT), err
+}
@@ -0,0 +1,70 @@
+package read
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// WithRESTMapper allows LoadGVK and ListGVK to verify that the kind is served by the cluster, and to ignore the configured namespace
+// for cluster-scoped kinds. The mapper of a manager can be obtained through its GetRESTMapper function
+func (this *resourceReader) WithRESTMapper(mapper meta.RESTMapper) *resourceReader {
+	this.mapper = mapper
+	return this
+}
+
+// LoadGVK returns the object of the given kind and name, in the previously configured namespace, as an unstructured object
+// so that kinds which are not registered in the scheme, for example from optional integrations, can be read as well.
+// If the kind is not served by the cluster, the error can be identified with meta.IsNoMatchError
+func (this *resourceReader) LoadGVK(gvk schema.GroupVersionKind, name string) (*unstructured.Unstructured, error) {
+	namespace, err := this.getNamespace(gvk)
+	if err != nil {
+		return nil, err
+	}
+	deployed := &unstructured.Unstructured{}
+	deployed.SetGroupVersionKind(gvk)
+	err = this.get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, deployed)
+	return deployed, err
+}
+
+// ListGVK returns a list of unstructured objects of the given kind, based on the configured namespace and owner object.
+// If the kind is not served by the cluster, the error can be identified with meta.IsNoMatchError
+func (this *resourceReader) ListGVK(gvk schema.GroupVersionKind) ([]client.Object, error) {
+	namespace, err := this.getNamespace(gvk)
+	if err != nil {
+		return nil, err
+	}
+	listObject := &unstructured.UnstructuredList{}
+	listObject.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	err = this.list(context.TODO(), listObject, &client.ListOptions{Namespace: namespace})
+	if err != nil {
+		return nil, err
+	}
+	var resources []client.Object
+	for index := range listObject.Items {
+		item := &listObject.Items[index]
+		if this.ownerObject == nil || isOwner(this.ownerObject, item) {
+			resources = append(resources, item)
+		}
+	}
+	return resources, nil
+}
+
+// getNamespace returns the configured namespace, unless the REST mapper identifies the kind as cluster-scoped
+func (this *resourceReader) getNamespace(gvk schema.GroupVersionKind) (string, error) {
+	if this.mapper == nil {
+		return this.namespace, nil
+	}
+	mapping, err := this.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return "", err
+	}
+	if mapping.Scope.Name() == meta.RESTScopeNameRoot {
+		return "", nil
+	}
+	return this.namespace, nil
+}
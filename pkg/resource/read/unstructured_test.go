@@ -0,0 +1,66 @@
+package read
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var knativeServiceGVK = schema.GroupVersionKind{Group: "serving.knative.dev", Version: "v1", Kind: "Service"}
+var istioMeshConfigGVK = schema.GroupVersionKind{Group: "install.istio.io", Version: "v1alpha1", Kind: "MeshConfig"}
+
+func TestLoadGVK(t *testing.T) {
+	services := getUnstructuredObjects(knativeServiceGVK, namespace, 2)
+	fakeClient := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(services[0], services[1]).Build()
+
+	reader := New(fakeClient).WithNamespace(namespace).WithRESTMapper(getRESTMapper())
+	found, err := reader.LoadGVK(knativeServiceGVK, services[1].GetName())
+	assert.Nil(t, err, "Expect no errors loading object")
+	assert.Equal(t, services[1].GetName(), found.GetName())
+	assert.Equal(t, knativeServiceGVK, found.GroupVersionKind())
+
+	_, err = reader.LoadGVK(schema.GroupVersionKind{Group: "unknown.io", Version: "v1", Kind: "Unknown"}, "name")
+	assert.True(t, meta.IsNoMatchError(err), "Expect a no-match error for a kind that is not served")
+}
+
+func TestListGVK(t *testing.T) {
+	services := getUnstructuredObjects(knativeServiceGVK, namespace, 2)
+	otherServices := getUnstructuredObjects(knativeServiceGVK, "other", 1)
+	meshConfigs := getUnstructuredObjects(istioMeshConfigGVK, "", 1)
+	fakeClient := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(services[0], services[1], otherServices[0], meshConfigs[0]).Build()
+
+	reader := New(fakeClient).WithNamespace(namespace).WithRESTMapper(getRESTMapper())
+	listed, err := reader.ListGVK(knativeServiceGVK)
+	assert.Nil(t, err, "Expect no errors listing objects")
+	assert.Len(t, listed, 2, "Expect to find 2 services in the namespace")
+	assert.IsType(t, &unstructured.Unstructured{}, listed[0], "Expect unstructured objects")
+
+	listed, err = reader.ListGVK(istioMeshConfigGVK)
+	assert.Nil(t, err, "Expect no errors listing objects")
+	assert.Len(t, listed, 1, "Expect the namespace to be ignored for a cluster-scoped kind")
+}
+
+func getRESTMapper() meta.RESTMapper {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(knativeServiceGVK, meta.RESTScopeNamespace)
+	mapper.Add(istioMeshConfigGVK, meta.RESTScopeRoot)
+	return mapper
+}
+
+func getUnstructuredObjects(gvk schema.GroupVersionKind, namespace string, count int) []*unstructured.Unstructured {
+	objects := make([]*unstructured.Unstructured, count)
+	for index := range objects {
+		objects[index] = &unstructured.Unstructured{}
+		objects[index].SetGroupVersionKind(gvk)
+		objects[index].SetName(fmt.Sprintf("%s-%d", strings.ToLower(gvk.Kind), index+1))
+		objects[index].SetNamespace(namespace)
+	}
+	return objects
+}
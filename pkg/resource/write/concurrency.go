@@ -0,0 +1,134 @@
+package write
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// WithConcurrency allows up to the provided number of writes to be made in parallel, within each dependency tier of the written objects
+// as determined by the tier function, so that for example a namespace is created before the objects it contains.
+// Requests remain subject to the rate limits of the client, so the effective throughput is bounded by its configured QPS.
+// Unlike serial writes, which stop at the first error, every write in a tier is attempted, and the errors of the tier are returned
+// as an aggregate, in the order of the provided objects. Later tiers are not written after a failed tier.
+// A value of 1, which is the default, writes the objects serially in the provided order, regardless of their tier
+func (this *resourceWriter) WithConcurrency(maxConcurrentWrites int) *resourceWriter {
+	this.concurrency = maxConcurrentWrites
+	return this
+}
+
+// WithTierFunc allows customizing the dependency tiers of objects written in parallel, where objects of a lower tier are written first
+// and removed last. By default, DefaultTier is used
+func (this *resourceWriter) WithTierFunc(tierFunc func(client.Object) int) *resourceWriter {
+	this.tierFunc = tierFunc
+	return this
+}
+
+var kindTiers = map[string]int{
+	"Namespace":                0,
+	"CustomResourceDefinition": 0,
+	"ServiceAccount":           1,
+	"ClusterRole":              1,
+	"Role":                     1,
+	"PriorityClass":            1,
+	"StorageClass":             1,
+	"ClusterRoleBinding":       2,
+	"RoleBinding":              2,
+	"ConfigMap":                2,
+	"Secret":                   2,
+	"PersistentVolume":         2,
+	"PersistentVolumeClaim":    2,
+	"ImageStream":              2,
+	"Service":                  3,
+}
+
+// DefaultTier returns the dependency tier of an object based on its kind, placing namespaces and CRDs first,
+// followed by accounts and roles, then configuration and storage, then services, and finally any other kind, such as workloads
+func DefaultTier(object client.Object) int {
+	if tier, found := kindTiers[getKind(object)]; found {
+		return tier
+	}
+	return len(kindTiers)
+}
+
+func getKind(object client.Object) string {
+	kind := object.GetObjectKind().GroupVersionKind().Kind
+	if kind == "" {
+		kind = reflect.ValueOf(object).Elem().Type().Name()
+	}
+	return kind
+}
+
+// writeAll applies the write function to each of the resources, either serially, or in parallel within dependency tiers
+// the boolean result is true if any of the writes were successful
+func (this *resourceWriter) writeAll(resources []client.Object, reverseTiers bool, write func(client.Object) error) (bool, error) {
+	var written bool
+	if this.concurrency <= 1 {
+		for index := range resources {
+			err := write(resources[index])
+			if err != nil {
+				return written, err
+			}
+			written = true
+		}
+		return written, nil
+	}
+	for _, tier := range this.getTiers(resources, reverseTiers) {
+		errs := make([]error, len(tier))
+		semaphore := make(chan struct{}, this.concurrency)
+		var waitGroup sync.WaitGroup
+		for index := range tier {
+			waitGroup.Add(1)
+			semaphore <- struct{}{}
+			go func(index int) {
+				defer waitGroup.Done()
+				defer func() { <-semaphore }()
+				errs[index] = write(tier[index])
+			}(index)
+		}
+		waitGroup.Wait()
+		var tierErrors []error
+		for index := range errs {
+			if errs[index] == nil {
+				written = true
+			} else {
+				tierErrors = append(tierErrors, fmt.Errorf("%s %s/%s: %w", getKind(tier[index]), tier[index].GetNamespace(), tier[index].GetName(), errs[index]))
+			}
+		}
+		if len(tierErrors) > 0 {
+			return written, utilerrors.NewAggregate(tierErrors)
+		}
+	}
+	return written, nil
+}
+
+// getTiers groups the resources by tier, in ascending order of tier unless reversed, keeping the provided order within each tier
+func (this *resourceWriter) getTiers(resources []client.Object, reverse bool) [][]client.Object {
+	tierFunc := this.tierFunc
+	if tierFunc == nil {
+		tierFunc = DefaultTier
+	}
+	tierMap := make(map[int][]client.Object)
+	var tierValues []int
+	for _, resource := range resources {
+		tier := tierFunc(resource)
+		if _, found := tierMap[tier]; !found {
+			tierValues = append(tierValues, tier)
+		}
+		tierMap[tier] = append(tierMap[tier], resource)
+	}
+	sort.Ints(tierValues)
+	tiers := make([][]client.Object, len(tierValues))
+	for index, tier := range tierValues {
+		if reverse {
+			tiers[len(tierValues)-1-index] = tierMap[tier]
+		} else {
+			tiers[index] = tierMap[tier]
+		}
+	}
+	return tiers
+}
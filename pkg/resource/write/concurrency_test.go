@@ -0,0 +1,113 @@
+package write
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestParallelAddResources(t *testing.T) {
+	cli := &recordingClient{Client: fake.NewClientBuilder().WithScheme(getScheme(t)).Build()}
+	var resources []client.Object
+	for index := 0; index < 10; index++ {
+		resources = append(resources, &corev1.Pod{ObjectMeta: v1.ObjectMeta{Name: fmt.Sprintf("pod-%d", index), Namespace: "namespace"}})
+	}
+	resources = append(resources, &corev1.ConfigMap{ObjectMeta: v1.ObjectMeta{Name: "config", Namespace: "namespace"}})
+	resources = append(resources, &corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: "namespace"}})
+
+	added, err := New(cli).WithConcurrency(3).AddResources(resources)
+	assert.Nil(t, err, "Expect no errors adding resources")
+	assert.True(t, added, "Expect resources to be added")
+	assert.Len(t, cli.created, 12, "Expect all resources to be created")
+	assert.Equal(t, "namespace", cli.created[0], "Expect the namespace to be created first")
+	assert.Equal(t, "config", cli.created[1], "Expect the config map to be created before the pods")
+	assert.LessOrEqual(t, cli.maxInFlight, 3, "Expect no more than 3 concurrent writes")
+	assert.Greater(t, cli.maxInFlight, 1, "Expect writes to be made in parallel")
+}
+
+func TestParallelAddResourcesErrors(t *testing.T) {
+	cli := &recordingClient{
+		Client:    fake.NewClientBuilder().WithScheme(getScheme(t)).Build(),
+		failNames: map[string]bool{"pod-5": true, "pod-2": true},
+	}
+	var resources []client.Object
+	for index := 0; index < 8; index++ {
+		resources = append(resources, &corev1.Pod{ObjectMeta: v1.ObjectMeta{Name: fmt.Sprintf("pod-%d", index), Namespace: "namespace"}})
+	}
+	resources = append(resources, &corev1.Service{ObjectMeta: v1.ObjectMeta{Name: "service", Namespace: "namespace"}})
+
+	added, err := New(cli).WithConcurrency(4).AddResources(resources)
+	assert.True(t, added, "Expect other resources in the failed tier to be added")
+	assert.Equal(t, "[Pod namespace/pod-2: create failed, Pod namespace/pod-5: create failed]", err.Error(), "Expect errors in the order of the provided resources")
+	assert.Len(t, cli.created, 7, "Expect the service, and all pods but the failed ones, to be created")
+
+	cli.failNames = map[string]bool{"service": true}
+	cli.created = nil
+	_, err = New(cli).WithConcurrency(4).AddResources(resources)
+	assert.NotNil(t, err, "Expect the service creation to fail")
+	assert.Empty(t, cli.created, "Expect no pods to be created after their dependency tier failed")
+}
+
+func TestParallelRemoveResources(t *testing.T) {
+	namespace := &corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: "namespace"}}
+	pod := &corev1.Pod{ObjectMeta: v1.ObjectMeta{Name: "pod", Namespace: "namespace"}}
+	cli := &recordingClient{Client: fake.NewClientBuilder().WithScheme(getScheme(t)).WithObjects(namespace, pod).Build()}
+
+	removed, err := New(cli).WithConcurrency(2).RemoveResources([]client.Object{namespace, pod})
+	assert.Nil(t, err, "Expect no errors removing resources")
+	assert.True(t, removed, "Expect resources to be removed")
+	assert.Equal(t, []string{"pod", "namespace"}, cli.deleted, "Expect the namespace to be removed last")
+}
+
+type recordingClient struct {
+	client.Client
+	failNames   map[string]bool
+	mutex       sync.Mutex
+	inFlight    int
+	maxInFlight int
+	created     []string
+	deleted     []string
+}
+
+func (this *recordingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	this.track(1)
+	defer this.track(-1)
+	time.Sleep(10 * time.Millisecond)
+	if this.failNames[obj.GetName()] {
+		return fmt.Errorf("create failed")
+	}
+	err := this.Client.Create(ctx, obj, opts...)
+	if err == nil {
+		this.mutex.Lock()
+		this.created = append(this.created, obj.GetName())
+		this.mutex.Unlock()
+	}
+	return err
+}
+
+func (this *recordingClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	err := this.Client.Delete(ctx, obj, opts...)
+	if err == nil {
+		this.mutex.Lock()
+		this.deleted = append(this.deleted, obj.GetName())
+		this.mutex.Unlock()
+	}
+	return err
+}
+
+func (this *recordingClient) track(delta int) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.inFlight += delta
+	if this.inFlight > this.maxInFlight {
+		this.maxInFlight = this.inFlight
+	}
+}
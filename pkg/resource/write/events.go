@@ -0,0 +1,55 @@
+package write
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	createdReason = "Created"
+	updatedReason = "Updated"
+	deletedReason = "Deleted"
+	failedSuffix  = "Failed"
+
+	maxEventFields = 10
+)
+
+var failedReasons = map[string]string{
+	createdReason: "Create" + failedSuffix,
+	updatedReason: "Update" + failedSuffix,
+	deletedReason: "Delete" + failedSuffix,
+}
+
+// WithEventRecorder allows an event to be recorded on the provided object, typically the custom resource that owns the written objects,
+// for each object that is created, updated or removed, as well as a warning for each failed write. Update events list the changed fields
+func (this *resourceWriter) WithEventRecorder(recorder record.EventRecorder, eventObject runtime.Object) *resourceWriter {
+	this.recorder = recorder
+	this.eventObject = eventObject
+	return this
+}
+
+func (this *resourceWriter) recordEvent(reason string, resource client.Object, changedFields []string, err error) {
+	if this.recorder == nil || this.eventObject == nil {
+		return
+	}
+	name := resource.GetName()
+	if resource.GetNamespace() != "" {
+		name = resource.GetNamespace() + "/" + name
+	}
+	if err != nil {
+		this.recorder.Eventf(this.eventObject, corev1.EventTypeWarning, failedReasons[reason], "Failed to write %s %s: %v", getKind(resource), name, err)
+		return
+	}
+	message := fmt.Sprintf("%s %s %s", reason, getKind(resource), name)
+	if len(changedFields) > maxEventFields {
+		message = fmt.Sprintf("%s: %s and %d more", message, strings.Join(changedFields[:maxEventFields], ", "), len(changedFields)-maxEventFields)
+	} else if len(changedFields) > 0 {
+		message = fmt.Sprintf("%s: %s", message, strings.Join(changedFields, ", "))
+	}
+	this.recorder.Event(this.eventObject, corev1.EventTypeNormal, reason, message)
+}
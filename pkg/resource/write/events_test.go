@@ -0,0 +1,48 @@
+package write
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestWriterEvents(t *testing.T) {
+	cli := fake.NewClientBuilder().WithScheme(getScheme(t)).Build()
+	recorder := record.NewFakeRecorder(10)
+	owner := &corev1.ConfigMap{ObjectMeta: v1.ObjectMeta{Name: "owner", Namespace: "namespace"}}
+	writer := New(cli).WithEventRecorder(recorder, owner)
+	failures := testutil.ToFloat64(writes.WithLabelValues("Service", "create", outcomeFailure))
+	updates := testutil.ToFloat64(writes.WithLabelValues("Service", "update", outcomeSuccess))
+
+	service := &corev1.Service{
+		ObjectMeta: v1.ObjectMeta{Name: "service1", Namespace: "namespace"},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 8080}}},
+	}
+	_, err := writer.AddResources([]client.Object{service})
+	assert.Nil(t, err, "Expect no errors creating object")
+	assert.Equal(t, "Normal Created Created Service namespace/service1", <-recorder.Events)
+
+	_, err = writer.AddResources([]client.Object{service.DeepCopy()})
+	assert.NotNil(t, err, "Expect an error creating an existing object")
+	assert.Contains(t, <-recorder.Events, "Warning CreateFailed Failed to write Service namespace/service1", "Expect a warning for the failed write")
+
+	updated := service.DeepCopy()
+	updated.Spec.Ports[0].Port = 8443
+	updated.Labels = map[string]string{"app": "service"}
+	_, err = writer.UpdateResources([]client.Object{service}, []client.Object{updated})
+	assert.Nil(t, err, "Expect no errors updating object")
+	assert.Equal(t, "Normal Updated Updated Service namespace/service1: metadata.labels.app, spec.ports[0].port", <-recorder.Events)
+
+	_, err = writer.RemoveResources([]client.Object{updated})
+	assert.Nil(t, err, "Expect no errors removing object")
+	assert.Equal(t, "Normal Deleted Deleted Service namespace/service1", <-recorder.Events)
+	assert.Empty(t, recorder.Events, "Expect no other events")
+	assert.Equal(t, failures+1, testutil.ToFloat64(writes.WithLabelValues("Service", "create", outcomeFailure)), "Expect the failed write to be counted")
+	assert.Equal(t, updates+1, testutil.ToFloat64(writes.WithLabelValues("Service", "update", outcomeSuccess)), "Expect the update to be counted")
+}
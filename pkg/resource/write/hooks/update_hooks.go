@@ -1,6 +1,7 @@
 package hooks
 
 import (
+	imagev1 "github.com/openshift/api/image/v1"
 	corev1 "k8s.io/api/core/v1"
 	"reflect"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -16,6 +17,7 @@ type UpdateHookMap struct {
 func DefaultUpdateHooks() *UpdateHookMap {
 	hookMap := make(map[reflect.Type]func(existing client.Object, requested client.Object) error)
 	hookMap[reflect.TypeOf(corev1.Service{})] = serviceHook
+	hookMap[reflect.TypeOf(imagev1.ImageStream{})] = imageStreamHook
 	return &UpdateHookMap{
 		DefaultHook: defaultHook,
 		HookMap:     hookMap,
@@ -48,3 +50,22 @@ func serviceHook(existing client.Object, requested client.Object) error {
 	}
 	return nil
 }
+
+func imageStreamHook(existing client.Object, requested client.Object) error {
+	existingImageStream := existing.(*imagev1.ImageStream)
+	requestedImageStream := requested.(*imagev1.ImageStream)
+	for index := range requestedImageStream.Spec.Tags {
+		requestedTag := &requestedImageStream.Spec.Tags[index]
+		if requestedTag.Generation != nil {
+			continue
+		}
+		for _, existingTag := range existingImageStream.Spec.Tags {
+			if existingTag.Name == requestedTag.Name && reflect.DeepEqual(existingTag.From, requestedTag.From) {
+				//Keep the tag generation, so that updating an unchanged tag does not trigger a new import
+				requestedTag.Generation = existingTag.Generation
+				break
+			}
+		}
+	}
+	return defaultHook(existing, requested)
+}
@@ -0,0 +1,40 @@
+package write
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	outcomeSuccess = "success"
+	outcomeFailure = "failure"
+)
+
+var writeVerbs = map[string]string{
+	createdReason: "create",
+	updatedReason: "update",
+	deletedReason: "delete",
+}
+
+var writes = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "operatorutils_writer_writes_total",
+		Help: "Number of objects written by the resource writer, by kind, verb and outcome",
+	},
+	[]string{"kind", "verb", "outcome"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(writes)
+}
+
+// recordWrite reports the outcome of writing the resource, through metrics and, if configured, an event
+func (this *resourceWriter) recordWrite(reason string, resource client.Object, changedFields []string, err error) {
+	outcome := outcomeSuccess
+	if err != nil {
+		outcome = outcomeFailure
+	}
+	writes.WithLabelValues(getKind(resource), writeVerbs[reason], outcome).Inc()
+	this.recordEvent(reason, resource, changedFields, err)
+}
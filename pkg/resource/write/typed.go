@@ -0,0 +1,21 @@
+package write
+
+import (
+	"github.com/RHsyseng/operator-utils/pkg/resource/compare"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AddTypedResources is the typed counterpart of AddResources, for example to add the objects of a compare.TypedResourceDelta
+func AddTypedResources[T client.Object](writer *resourceWriter, resources []T) (bool, error) {
+	return writer.AddResources(compare.ToObjects(resources))
+}
+
+// UpdateTypedResources is the typed counterpart of UpdateResources, for example to update the objects of a compare.TypedResourceDelta
+func UpdateTypedResources[T client.Object](writer *resourceWriter, existing []T, resources []T) (bool, error) {
+	return writer.UpdateResources(compare.ToObjects(existing), compare.ToObjects(resources))
+}
+
+// RemoveTypedResources is the typed counterpart of RemoveResources, for example to remove the objects of a compare.TypedResourceDelta
+func RemoveTypedResources[T client.Object](writer *resourceWriter, resources []T) (bool, error) {
+	return writer.RemoveResources(compare.ToObjects(resources))
+}
@@ -2,14 +2,19 @@ package write
 
 import (
 	"context"
+	"github.com/RHsyseng/operator-utils/pkg/resource/compare"
 	"github.com/RHsyseng/operator-utils/pkg/resource/write/hooks"
 	newerror "github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
+var logger = ctrl.Log.WithName("writer")
+
 type UpdateHooks interface {
 	Trigger(existing client.Object, requested client.Object) error
 }
@@ -20,6 +25,10 @@ type resourceWriter struct {
 	ownerController metav1.Object
 	scheme          *runtime.Scheme
 	updateHooks     UpdateHooks
+	concurrency     int
+	recorder        record.EventRecorder
+	eventObject     runtime.Object
+	tierFunc        func(client.Object) int
 }
 
 // New creates a resourceWriter object that can be used to add/update/remove kubernetes resources
@@ -59,24 +68,21 @@ func (this *resourceWriter) WithCustomUpdateHooks(updateHooks UpdateHooks) *reso
 // AddResources sets ownership as/if configured, and then uses the writer to create them
 // the boolean result is true if any changes were made
 func (this *resourceWriter) AddResources(resources []client.Object) (bool, error) {
-	var added bool
-	for index := range resources {
-		requested := resources[index]
-		if this.ownerRefs != nil {
-			requested.SetOwnerReferences(this.ownerRefs)
-		} else if this.canSetOwnerRef(requested, this.ownerController) {
-			err := controllerutil.SetControllerReference(this.ownerController, requested, this.scheme)
-			if err != nil {
-				return added, err
-			}
-		}
-		err := this.writer.Create(context.TODO(), requested)
+	return this.writeAll(resources, false, this.addResource)
+}
+
+func (this *resourceWriter) addResource(requested client.Object) error {
+	if this.ownerRefs != nil {
+		requested.SetOwnerReferences(this.ownerRefs)
+	} else if this.canSetOwnerRef(requested, this.ownerController) {
+		err := controllerutil.SetControllerReference(this.ownerController, requested, this.scheme)
 		if err != nil {
-			return added, err
+			return err
 		}
-		added = true
 	}
-	return added, nil
+	err := this.writer.Create(context.TODO(), requested)
+	this.recordWrite(createdReason, requested, nil, err)
+	return err
 }
 
 func (this *resourceWriter) canSetOwnerRef(resource metav1.Object, owner metav1.Object) bool {
@@ -93,50 +99,54 @@ func (this *resourceWriter) canSetOwnerRef(resource metav1.Object, owner metav1.
 // It also sets ownership as/if configured, and then uses the writer to update them
 // the boolean result is true if any changes were made
 func (this *resourceWriter) UpdateResources(existing []client.Object, resources []client.Object) (bool, error) {
-	var updated bool
-	for index := range resources {
-		requested := resources[index]
-		var counterpart client.Object
-		for _, candidate := range existing {
-			if candidate.GetNamespace() == requested.GetNamespace() && candidate.GetName() == requested.GetName() {
-				counterpart = candidate
-				break
-			}
-		}
-		if counterpart == nil {
-			return updated, newerror.New("Failed to find a deployed counterpart to resource being updated")
+	return this.writeAll(resources, false, func(requested client.Object) error {
+		return this.updateResource(existing, requested)
+	})
+}
+
+func (this *resourceWriter) updateResource(existing []client.Object, requested client.Object) error {
+	var counterpart client.Object
+	for _, candidate := range existing {
+		if candidate.GetNamespace() == requested.GetNamespace() && candidate.GetName() == requested.GetName() {
+			counterpart = candidate
+			break
 		}
-		err := this.updateHooks.Trigger(counterpart, requested)
+	}
+	if counterpart == nil {
+		return newerror.New("Failed to find a deployed counterpart to resource being updated")
+	}
+	err := this.updateHooks.Trigger(counterpart, requested)
+	if err != nil {
+		return err
+	}
+	if this.ownerRefs != nil {
+		requested.SetOwnerReferences(this.ownerRefs)
+	} else if this.ownerController != nil {
+		err := controllerutil.SetControllerReference(this.ownerController, requested, this.scheme)
 		if err != nil {
-			return updated, err
-		}
-		if this.ownerRefs != nil {
-			requested.SetOwnerReferences(this.ownerRefs)
-		} else if this.ownerController != nil {
-			err := controllerutil.SetControllerReference(this.ownerController, requested, this.scheme)
-			if err != nil {
-				return updated, err
-			}
+			return err
 		}
-		err = this.writer.Update(context.TODO(), requested)
+	}
+	var changedFields []string
+	if this.recorder != nil {
+		changedFields, err = compare.GetChangedFields(counterpart, requested)
 		if err != nil {
-			return updated, err
+			logger.Error(err, "Failed to find the changed fields of the updated resource", "name", requested.GetName())
 		}
-		updated = true
 	}
-	return updated, nil
+	err = this.writer.Update(context.TODO(), requested)
+	this.recordWrite(updatedReason, requested, changedFields, err)
+	return err
 }
 
 // RemoveResources removes each of the provided resources using the provided writer
 // the boolean result is true if any changes were made
 func (this *resourceWriter) RemoveResources(resources []client.Object) (bool, error) {
-	var removed bool
-	for index := range resources {
-		err := this.writer.Delete(context.TODO(), resources[index])
-		if err != nil {
-			return removed, err
-		}
-		removed = true
-	}
-	return removed, nil
+	return this.writeAll(resources, true, this.removeResource)
+}
+
+func (this *resourceWriter) removeResource(resource client.Object) error {
+	err := this.writer.Delete(context.TODO(), resource)
+	this.recordWrite(deletedReason, resource, nil, err)
+	return err
 }
@@ -2,6 +2,7 @@ package write
 
 import (
 	"context"
+	imagev1 "github.com/openshift/api/image/v1"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -92,9 +93,50 @@ func TestUpdateService(t *testing.T) {
 	assert.Equal(t, updatedService, existingService, "Expected Cluster IP to be set on the updating object")
 }
 
+func TestUpdateImageStream(t *testing.T) {
+	scheme := getScheme(t)
+	cli := fake.NewClientBuilder().WithScheme(scheme).Build()
+	generation := int64(3)
+	existingImageStream := imagev1.ImageStream{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "is1",
+			Namespace: "namespace",
+		},
+		Spec: imagev1.ImageStreamSpec{
+			Tags: []imagev1.TagReference{
+				{Name: "latest", From: &corev1.ObjectReference{Kind: "DockerImage", Name: "image:latest"}, Generation: &generation},
+				{Name: "1.0", From: &corev1.ObjectReference{Kind: "DockerImage", Name: "image:1.0"}, Generation: &generation},
+			},
+		},
+	}
+	added, err := New(cli).AddResources([]client.Object{&existingImageStream})
+	assert.Nil(t, err, "Expect no errors creating a simple object")
+	assert.True(t, added, "Object should be added")
+
+	updatedImageStream := imagev1.ImageStream{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "is1",
+			Namespace: "namespace",
+		},
+		Spec: imagev1.ImageStreamSpec{
+			Tags: []imagev1.TagReference{
+				{Name: "latest", From: &corev1.ObjectReference{Kind: "DockerImage", Name: "image:latest"}},
+				{Name: "1.0", From: &corev1.ObjectReference{Kind: "DockerImage", Name: "image:1.1"}},
+			},
+		},
+	}
+	updated, err := New(cli).UpdateResources([]client.Object{&existingImageStream}, []client.Object{&updatedImageStream})
+	assert.Nil(t, err, "Expect no errors updating object")
+	assert.True(t, updated, "Object should be updated")
+	assert.Equal(t, &generation, updatedImageStream.Spec.Tags[0].Generation, "Expected generation of unchanged tag to be kept")
+	assert.Nil(t, updatedImageStream.Spec.Tags[1].Generation, "Expected generation of changed tag not to be set")
+}
+
 func getScheme(t *testing.T) *runtime.Scheme {
 	scheme := runtime.NewScheme()
 	err := corev1.SchemeBuilder.AddToScheme(scheme)
 	assert.Nil(t, err, "Expect no errors building scheme")
+	err = imagev1.Install(scheme)
+	assert.Nil(t, err, "Expect no errors building scheme")
 	return scheme
 }
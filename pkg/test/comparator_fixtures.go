@@ -0,0 +1,107 @@
+package test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/RHsyseng/operator-utils/pkg/resource/compare"
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	clientv1 "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	DeployedFixtureFile  = "deployed.yaml"
+	RequestedFixtureFile = "requested.yaml"
+	ExpectedFixtureFile  = "expected.yaml"
+)
+
+// ComparatorResult is the golden content of a comparator fixture, recording the outcome of comparing its objects,
+// and the fields that the requested object changes when the comparator deems them different
+type ComparatorResult struct {
+	Equal bool     `json:"equal"`
+	Diffs []string `json:"diffs,omitempty"`
+}
+
+// RunComparatorFixtures runs a subtest for each subdirectory of dir, comparing the objects in its deployed.yaml and requested.yaml files
+// with the provided comparator. The outcome, along with the fields that the requested object changes as reported by compare.GetChangedFields,
+// is asserted against expected.yaml, which is instead written with the actual outcome when update is true, typically set from a test flag.
+// Objects are decoded based on the provided scheme, so real cluster objects can be captured with `kubectl get -o yaml`
+func RunComparatorFixtures(t *testing.T, dir string, scheme *runtime.Scheme, comparator compare.ResourceComparator, update bool) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read comparator fixtures: %v", err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		fixtureDir := filepath.Join(dir, entry.Name())
+		t.Run(entry.Name(), func(t *testing.T) {
+			runComparatorFixture(t, fixtureDir, scheme, comparator, update)
+		})
+	}
+}
+
+func runComparatorFixture(t *testing.T, dir string, scheme *runtime.Scheme, comparator compare.ResourceComparator, update bool) {
+	deployed, err := LoadObject(filepath.Join(dir, DeployedFixtureFile), scheme)
+	if err != nil {
+		t.Fatalf("failed to load deployed object: %v", err)
+	}
+	requested, err := LoadObject(filepath.Join(dir, RequestedFixtureFile), scheme)
+	if err != nil {
+		t.Fatalf("failed to load requested object: %v", err)
+	}
+	actual := ComparatorResult{Equal: comparator.Compare(deployed, requested)}
+	if !actual.Equal {
+		actual.Diffs, err = compare.GetChangedFields(deployed, requested)
+		if err != nil {
+			t.Fatalf("failed to compute changed fields: %v", err)
+		}
+	}
+
+	expectedFile := filepath.Join(dir, ExpectedFixtureFile)
+	if update {
+		content, err := yaml.Marshal(actual)
+		if err != nil {
+			t.Fatalf("failed to marshal comparator result: %v", err)
+		}
+		err = os.WriteFile(expectedFile, content, 0644)
+		if err != nil {
+			t.Fatalf("failed to write comparator result: %v", err)
+		}
+		return
+	}
+	content, err := os.ReadFile(expectedFile)
+	if err != nil {
+		t.Fatalf("failed to read expected comparator result, run the tests in update mode to create it: %v", err)
+	}
+	expected := ComparatorResult{}
+	err = yaml.Unmarshal(content, &expected)
+	if err != nil {
+		t.Fatalf("failed to parse expected comparator result: %v", err)
+	}
+	assert.Equal(t, expected.Equal, actual.Equal, "Unexpected comparator result")
+	assert.Equal(t, expected.Diffs, actual.Diffs, "Unexpected changed fields")
+}
+
+// LoadObject decodes the YAML or JSON file into a typed object registered in the provided scheme
+func LoadObject(file string, scheme *runtime.Scheme) (clientv1.Object, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	decoded, _, err := serializer.NewCodecFactory(scheme).UniversalDeserializer().Decode(content, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	object, ok := decoded.(clientv1.Object)
+	if !ok {
+		return nil, fmt.Errorf("%s does not contain a Kubernetes object", file)
+	}
+	return object, nil
+}
@@ -0,0 +1,173 @@
+package test
+
+import (
+	"strings"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	discoveryFake "k8s.io/client-go/discovery/fake"
+	k8sTesting "k8s.io/client-go/testing"
+)
+
+// FakeDiscovery is a discovery client serving the kinds that are installed through it, so that tests can simulate CRDs being
+// installed and removed while a detector runs. Unlike the fake of client-go, it can be changed while it is used by other goroutines
+type FakeDiscovery struct {
+	*discoveryFake.FakeDiscovery
+	mutex  sync.RWMutex
+	errors map[string]error
+}
+
+// FakeDiscoveryBuilder creates a FakeDiscovery with the initially installed kinds and server version
+type FakeDiscoveryBuilder struct {
+	gvks          []schema.GroupVersionKind
+	serverVersion *version.Info
+}
+
+// NewFakeDiscoveryBuilder creates a builder of a FakeDiscovery that serves no kinds
+func NewFakeDiscoveryBuilder() *FakeDiscoveryBuilder {
+	return &FakeDiscoveryBuilder{}
+}
+
+// WithKinds sets kinds to be initially served, where the first version of a group is the one that the server prefers
+func (builder *FakeDiscoveryBuilder) WithKinds(gvks ...schema.GroupVersionKind) *FakeDiscoveryBuilder {
+	builder.gvks = append(builder.gvks, gvks...)
+	return builder
+}
+
+// WithServerVersion sets the Kubernetes version reported by the server, for example "1", "26"
+func (builder *FakeDiscoveryBuilder) WithServerVersion(major string, minor string) *FakeDiscoveryBuilder {
+	builder.serverVersion = &version.Info{Major: major, Minor: minor, GitVersion: "v" + major + "." + minor + ".0"}
+	return builder
+}
+
+// Build creates the FakeDiscovery
+func (builder *FakeDiscoveryBuilder) Build() *FakeDiscovery {
+	fake := &FakeDiscovery{
+		FakeDiscovery: &discoveryFake.FakeDiscovery{Fake: &k8sTesting.Fake{}, FakedServerVersion: builder.serverVersion},
+		errors:        map[string]error{},
+	}
+	fake.Install(builder.gvks...)
+	return fake
+}
+
+// Install starts serving the kinds, as happens when their CRDs are installed
+func (fake *FakeDiscovery) Install(gvks ...schema.GroupVersionKind) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	for _, gvk := range gvks {
+		apiList := fake.getAPIList(gvk.GroupVersion().String())
+		if apiList == nil {
+			apiList = &metav1.APIResourceList{GroupVersion: gvk.GroupVersion().String()}
+			fake.Resources = append(fake.Resources, apiList)
+		}
+		if !hasKind(apiList, gvk.Kind) {
+			apiList.APIResources = append(apiList.APIResources, metav1.APIResource{
+				Name:       strings.ToLower(gvk.Kind) + "s",
+				Kind:       gvk.Kind,
+				Group:      gvk.Group,
+				Version:    gvk.Version,
+				Namespaced: true,
+				Verbs:      metav1.Verbs{"create", "delete", "get", "list", "patch", "update", "watch"},
+			})
+		}
+	}
+}
+
+// Uninstall stops serving the kinds, as happens when their CRDs are removed, along with their group version once it serves no kinds
+func (fake *FakeDiscovery) Uninstall(gvks ...schema.GroupVersionKind) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	for _, gvk := range gvks {
+		apiList := fake.getAPIList(gvk.GroupVersion().String())
+		if apiList == nil {
+			continue
+		}
+		resources := make([]metav1.APIResource, 0, len(apiList.APIResources))
+		for _, resource := range apiList.APIResources {
+			if resource.Kind != gvk.Kind {
+				resources = append(resources, resource)
+			}
+		}
+		apiList.APIResources = resources
+	}
+	apiLists := make([]*metav1.APIResourceList, 0, len(fake.Resources))
+	for _, apiList := range fake.Resources {
+		if len(apiList.APIResources) > 0 {
+			apiLists = append(apiLists, apiList)
+		}
+	}
+	fake.Resources = apiLists
+}
+
+// SetError sets the error returned when discovering the group version, or when listing the served groups for an empty group version,
+// and a nil error clears it
+func (fake *FakeDiscovery) SetError(groupVersion schema.GroupVersion, err error) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	if err == nil {
+		delete(fake.errors, groupVersion.String())
+	} else {
+		fake.errors[groupVersion.String()] = err
+	}
+}
+
+func (fake *FakeDiscovery) ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error) {
+	fake.mutex.RLock()
+	defer fake.mutex.RUnlock()
+	if err := fake.errors[groupVersion]; err != nil {
+		return nil, err
+	}
+	apiList, err := fake.FakeDiscovery.ServerResourcesForGroupVersion(groupVersion)
+	if err != nil {
+		return nil, err
+	}
+	return apiList.DeepCopy(), nil
+}
+
+func (fake *FakeDiscovery) ServerGroups() (*metav1.APIGroupList, error) {
+	fake.mutex.RLock()
+	defer fake.mutex.RUnlock()
+	if err := fake.errors[""]; err != nil {
+		return nil, err
+	}
+	return fake.FakeDiscovery.ServerGroups()
+}
+
+func (fake *FakeDiscovery) ServerGroupsAndResources() ([]*metav1.APIGroup, []*metav1.APIResourceList, error) {
+	fake.mutex.RLock()
+	defer fake.mutex.RUnlock()
+	return fake.FakeDiscovery.ServerGroupsAndResources()
+}
+
+func (fake *FakeDiscovery) ServerPreferredResources() ([]*metav1.APIResourceList, error) {
+	fake.mutex.RLock()
+	defer fake.mutex.RUnlock()
+	return fake.FakeDiscovery.ServerPreferredResources()
+}
+
+func (fake *FakeDiscovery) ServerPreferredNamespacedResources() ([]*metav1.APIResourceList, error) {
+	fake.mutex.RLock()
+	defer fake.mutex.RUnlock()
+	return fake.FakeDiscovery.ServerPreferredNamespacedResources()
+}
+
+// getAPIList returns the served resources of the group version, and must be called while holding the lock
+func (fake *FakeDiscovery) getAPIList(groupVersion string) *metav1.APIResourceList {
+	for _, apiList := range fake.Resources {
+		if apiList.GroupVersion == groupVersion {
+			return apiList
+		}
+	}
+	return nil
+}
+
+func hasKind(apiList *metav1.APIResourceList, kind string) bool {
+	for _, resource := range apiList.APIResources {
+		if resource.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -3,10 +3,17 @@ package kubernetes
 import (
 	"context"
 	"fmt"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"strings"
+	"time"
+)
+
+const (
+	finalizedReason       = "Finalized"
+	finalizerFailedReason = "FinalizerFailed"
 )
 
 type Finalizer interface {
@@ -55,21 +62,34 @@ func (e *ExtendedReconciler) finalizeOnDelete(owner client.Object) error {
 	for _, f := range owner.GetFinalizers() {
 		finalizer := e.Finalizers[f]
 		if finalizer != nil {
+			start := time.Now()
 			err := finalizer.OnFinalize(owner, e.Service)
+			finalizerDuration.WithLabelValues(f).Observe(time.Since(start).Seconds())
 			if err != nil {
+				finalizerFailures.WithLabelValues(f).Inc()
+				e.recordEvent(owner, corev1.EventTypeWarning, finalizerFailedReason, "Finalizer %s failed: %v", f, err)
 				return err
 			}
 			err = e.removeFinalizer(owner, f)
 			if err != nil {
+				e.recordEvent(owner, corev1.EventTypeWarning, finalizerFailedReason, "Failed to remove finalizer %s: %v", f, err)
 				return err
 			}
+			e.recordEvent(owner, corev1.EventTypeNormal, finalizedReason, "Finalizer %s completed", f)
 		} else {
-			return fmt.Errorf("finalizer %s does not have a Finalizer handler registered", finalizer)
+			e.recordEvent(owner, corev1.EventTypeWarning, finalizerFailedReason, "Finalizer %s does not have a handler registered", f)
+			return fmt.Errorf("finalizer %s does not have a Finalizer handler registered", f)
 		}
 	}
 	return nil
 }
 
+func (e *ExtendedReconciler) recordEvent(owner client.Object, eventType string, reason string, messageFmt string, args ...interface{}) {
+	if e.Recorder != nil {
+		e.Recorder.Eventf(owner, eventType, reason, messageFmt, args...)
+	}
+}
+
 func validateFinalizerName(name string) error {
 	if len(strings.TrimSpace(name)) == 0 {
 		return fmt.Errorf("the finalizer name must not be empty")
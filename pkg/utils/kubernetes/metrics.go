@@ -0,0 +1,28 @@
+package kubernetes
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	finalizerDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "operatorutils_finalizer_duration_seconds",
+			Help:    "Duration of each run of a finalizer registered with the extended reconciler, by finalizer",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"finalizer"},
+	)
+	finalizerFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "operatorutils_finalizer_failures_total",
+			Help: "Number of failed runs of a finalizer registered with the extended reconciler, by finalizer",
+		},
+		[]string{"finalizer"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(finalizerDuration, finalizerFailures)
+}
@@ -3,6 +3,7 @@ package kubernetes
 import (
 	"context"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -12,6 +13,8 @@ type ExtendedReconciler struct {
 	Reconciler reconcile.Reconciler
 	Resource   client.Object
 	Finalizers map[string]Finalizer
+	// Recorder, when set, is used to record events on the resource when its finalizers run or fail
+	Recorder record.EventRecorder
 }
 
 func NewExtendedReconciler(service PlatformService, reconciler reconcile.Reconciler, resource client.Object) ExtendedReconciler {
@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"github.com/RHsyseng/operator-utils/pkg/test"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"testing"
@@ -220,6 +222,37 @@ func TestExtendedReconciler_FinalizeOnDeleteErrorOnUpdate(t *testing.T) {
 	assert.Len(t, extReconciler.Finalizers, 1)
 }
 
+func TestExtendedReconciler_FinalizeOnDeleteEvents(t *testing.T) {
+	extReconciler := BuildTestExtendedReconciler()
+	recorder := record.NewFakeRecorder(10)
+	extReconciler.Recorder = recorder
+	extReconciler.Finalizers = map[string]Finalizer{
+		"f1": &MockFinalizer{},
+		"f2": &MockFinalizer{
+			onFinalizeFn: func(owner client.Object, service PlatformService) error {
+				return fmt.Errorf("Foo error")
+			},
+		},
+	}
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "somepod",
+			Namespace: "somenamespace",
+		},
+	}
+	pod.SetFinalizers([]string{"f1", "f2"})
+	pod.SetDeletionTimestamp(&metav1.Time{})
+	extReconciler.Service.Create(context.TODO(), pod)
+
+	failures := testutil.ToFloat64(finalizerFailures.WithLabelValues("f2"))
+	err := extReconciler.finalizeOnDelete(pod)
+	assert.Errorf(t, err, "Foo error")
+	assert.Equal(t, failures+1, testutil.ToFloat64(finalizerFailures.WithLabelValues("f2")), "Expect the failure to be counted")
+	assert.Equal(t, "Normal Finalized Finalizer f1 completed", <-recorder.Events)
+	assert.Equal(t, "Warning FinalizerFailed Finalizer f2 failed: Foo error", <-recorder.Events)
+}
+
 func TestExtendedReconciler_Reconcile(t *testing.T) {
 	extReconciler := BuildTestExtendedReconciler()
 	var f1Invoked, f2Invoked bool
@@ -7,6 +7,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 )
 
+// CustomResourceDefinitionExists queries the API server on each call, so in hot paths, such as reconcile loops,
+// prefer querying the Capabilities populated by a detector, see detector.WithCapabilities
 func CustomResourceDefinitionExists(gvk schema.GroupVersionKind) error {
 	cfg, err := config.GetConfig()
 	if err != nil {